@@ -0,0 +1,135 @@
+package attributes
+
+import (
+	"bytes"
+	"git-light/application/repository"
+	"git-light/util"
+	"path"
+	"strings"
+)
+
+const (
+	textUnset = iota
+	textSet
+	textAuto
+	textOff
+)
+
+type AttributeResolver interface {
+	Clean(p string, content []byte) []byte
+	Smudge(p string, content []byte) []byte
+}
+
+type rule struct {
+	pattern string
+	text    int
+	eol     string
+}
+
+type attributeResolver struct {
+	rules []rule
+}
+
+// NewAttributeResolver reads line ending rules from attributes file in working directory,
+// a missing file means no conversion is done at all.
+func NewAttributeResolver(repo repository.Repository) AttributeResolver {
+	lines, err := repo.GetFileLines(util.AttributesFile)
+	if err != nil {
+		return attributeResolver{rules: make([]rule, 0)}
+	}
+
+	return attributeResolver{rules: parseRules(lines)}
+}
+
+// Clean converts CRLF line endings to LF before content is staged if path is a text file.
+func (ar attributeResolver) Clean(p string, content []byte) []byte {
+	if !ar.isText(p, content) {
+		return content
+	}
+
+	return bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+}
+
+// Smudge converts LF line endings to CRLF while writing to working directory if eol=crlf
+// is set for path, otherwise content is written as it is stored.
+func (ar attributeResolver) Smudge(p string, content []byte) []byte {
+	if !ar.isText(p, content) || ar.eolOf(p) != "crlf" {
+		return content
+	}
+
+	normalized := bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(normalized, []byte("\n"), []byte("\r\n"))
+}
+
+func (ar attributeResolver) isText(p string, content []byte) bool {
+	text := textUnset
+	for _, r := range ar.rules {
+		if r.text != textUnset && matches(r.pattern, p) {
+			text = r.text
+		}
+	}
+
+	switch text {
+	case textSet:
+		return true
+	case textAuto:
+		return bytes.IndexByte(content[:min(len(content), 8000)], 0) == -1
+	case textOff:
+		return false
+	}
+
+	return ar.eolOf(p) != ""
+}
+
+func (ar attributeResolver) eolOf(p string) string {
+	eol := ""
+	for _, r := range ar.rules {
+		if r.eol != "" && matches(r.pattern, p) {
+			eol = r.eol
+		}
+	}
+
+	return eol
+}
+
+func parseRules(lines []string) []rule {
+	rules := make([]rule, 0)
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		r := rule{pattern: fields[0]}
+		for _, attribute := range fields[1:] {
+			switch attribute {
+			case "text":
+				r.text = textSet
+			case "text=auto":
+				r.text = textAuto
+			case "-text", "binary":
+				r.text = textOff
+			case "eol=lf":
+				r.eol = "lf"
+			case "eol=crlf":
+				r.eol = "crlf"
+			}
+		}
+		rules = append(rules, r)
+	}
+
+	return rules
+}
+
+// matches reports whether pattern matches given slash separated path. patterns without
+// a slash are matched against file name only, like gitattributes does.
+func matches(pattern, p string) bool {
+	p = strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(p, "\\", "/")), "/")
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(p))
+		return matched
+	}
+
+	matched, _ := path.Match(strings.TrimPrefix(pattern, "/"), p)
+	return matched
+}
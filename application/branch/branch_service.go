@@ -1,70 +1,115 @@
 package branch
 
 import (
+	"errors"
 	"fmt"
+	"git-light/application/ref"
 	"git-light/application/repository"
+	"git-light/application/revision"
 	"git-light/util"
-	"log"
 	"path/filepath"
 )
 
 type BranchService interface {
-	CreateBranch(branchName string)
-	DeleteBranch(branchName string)
-	ListAllBranches() []string
+	CreateBranch(branchName string, startPoint string) error
+	DeleteBranch(branchName string) error
+	ListAllBranches() ([]string, error)
 }
 
 type branchService struct {
-	repo repository.Repository
+	repo      repository.Repository
+	refs      ref.RefStore
+	revisions revision.RevisionResolver
 }
 
-func NewBranchService(repo repository.Repository) BranchService {
+func NewBranchService(repo repository.Repository, refs ref.RefStore, revisions revision.RevisionResolver) BranchService {
 	return branchService{
-		repo: repo,
+		repo:      repo,
+		refs:      refs,
+		revisions: revisions,
 	}
 }
 
-func (bs branchService) CreateBranch(branchName string) {
-	lines, err := bs.repo.GetFileLines(filepath.Join(util.BaseFilePath, util.Head))
+// CreateBranch creates a branch pointing to given start point revision, current commit is
+// used when start point is empty.
+func (bs branchService) CreateBranch(branchName string, startPoint string) error {
+	if !ref.IsValidName(branchName) {
+		return errors.New("invalid branch name: " + branchName)
+	}
+	head, err := bs.refs.ReadHead()
 	if err != nil {
-		log.Fatal("couldn't get HEAD err: " + err.Error())
+		return fmt.Errorf("couldn't get HEAD: %w", err)
+	}
+	if bs.refs.BranchExists(branchName) {
+		return errors.New("a branch named " + branchName + " already exists")
 	}
 
-	commitHash, err := bs.repo.GetFileLines(filepath.Join(util.BaseFilePath, util.BranchFolder, lines[0]))
-	if err != nil {
-		commitHash[0] = lines[0]
+	commitHash := head.Hash
+	reason := "branch: Created from HEAD"
+	if startPoint != "" {
+		reason = "branch: Created from " + startPoint
+		commitHash, err = bs.revisions.Resolve(startPoint)
+		if err != nil {
+			return err
+		}
 	}
 
-	err = bs.repo.WriteToFile(filepath.Join(util.BaseFilePath, util.BranchFolder, branchName), commitHash)
+	err = bs.refs.WriteBranch(branchName, commitHash, reason)
 	if err != nil {
-		log.Fatal("couldn't create new branch. err: " + err.Error())
+		return fmt.Errorf("couldn't create new branch: %w", err)
 	}
+
+	return nil
 }
 
-func (bs branchService) DeleteBranch(branchName string) {
-	lines, err := bs.repo.GetFileLines(filepath.Join(util.BaseFilePath, util.Head))
+func (bs branchService) DeleteBranch(branchName string) error {
+	head, err := bs.refs.ReadHead()
 	if err != nil {
-		log.Fatal("couldn't get HEAD err: " + err.Error())
+		return fmt.Errorf("couldn't get HEAD: %w", err)
+	}
+
+	if head.Branch == branchName {
+		return errors.New("couldn't delete branch. you should checkout different branch before deleting it")
 	}
 
-	if lines[0] == branchName {
-		log.Fatal("couldn't delete branch. you should checkout different branch before deleting it.")
+	commitHash, err := bs.refs.ReadBranch(branchName)
+	if err != nil {
+		return err
 	}
 
-	err = bs.repo.DeleteFiles(filepath.Join(util.BaseFilePath, util.BranchFolder, branchName))
+	err = bs.refs.DeleteBranch(branchName)
 	if err != nil {
-		log.Fatal("couldn't delete branch. err: " + err.Error())
+		return fmt.Errorf("couldn't delete branch: %w", err)
 	}
+	fmt.Printf("Deleted branch %s (was %s)\n", branchName, commitHash)
+
+	return nil
 }
 
-func (bs branchService) ListAllBranches() []string {
-	allBranches, err := bs.repo.ListAllFiles(filepath.Join(util.BaseFilePath, util.BranchFolder))
+// ListAllBranches prints branch names marking the current one, detached HEAD is listed
+// on top of them.
+func (bs branchService) ListAllBranches() ([]string, error) {
+	head, err := bs.refs.ReadHead()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get HEAD: %w", err)
+	}
+
+	branchesDir := filepath.Join(util.BaseFilePath, util.BranchFolder)
+	allBranches, err := bs.repo.ListAllFiles(branchesDir)
 	if err != nil {
-		log.Fatal("couldn't get list of branches. err: " + err.Error())
+		return nil, fmt.Errorf("couldn't get list of branches: %w", err)
 	}
 
-	for _, branch := range allBranches {
-		fmt.Println(filepath.Base(branch))
+	if head.IsDetached() {
+		fmt.Printf("* (HEAD detached at %s)\n", head.Hash)
+	}
+	for i, branch := range allBranches {
+		allBranches[i], _ = filepath.Rel(branchesDir, branch)
+		if allBranches[i] == head.Branch {
+			fmt.Println("* " + allBranches[i])
+		} else {
+			fmt.Println("  " + allBranches[i])
+		}
 	}
-	return allBranches
+	return allBranches, nil
 }
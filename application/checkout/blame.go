@@ -0,0 +1,208 @@
+package checkout
+
+import (
+	"errors"
+	"fmt"
+	"git-light/util"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Blame prints every line of a file in given revision, or HEAD, together with the commit
+// which introduced it. lineRange limits output to lines like 10,20 or 10,+5.
+func (cs commitService) Blame(path string, rev string, lineRange string) error {
+	if rev == "" {
+		rev = util.Head
+	}
+	path = filepath.Clean(path)
+	commitHash, err := cs.revisions.Resolve(rev)
+	if err != nil {
+		return err
+	}
+
+	commits, err := cs.collectLogCommits([]string{commitHash}, map[string]bool{})
+	if err != nil {
+		return err
+	}
+	blobHash, ok := commits[commitHash].GetAllFilePaths()[path]
+	if !ok {
+		return errors.New("no such path " + path + " in " + rev)
+	}
+	blob, err := cs.readBlob(blobHash)
+	if err != nil {
+		return err
+	}
+	if blob.Binary {
+		return errors.New("can not blame binary file: " + path)
+	}
+
+	lines, err := cs.ExtractFileFromObjectStore(blobHash)
+	if err != nil {
+		return err
+	}
+	start, end, err := parseLineRange(lineRange, len(lines))
+	if err != nil {
+		return err
+	}
+	owners, err := cs.blameLines(path, commitHash, commits, len(lines))
+	if err != nil {
+		return err
+	}
+
+	committerWidth := 0
+	for _, owner := range owners[start:end] {
+		committerWidth = max(committerWidth, len(commits[owner].Committer))
+	}
+	numberWidth := len(strconv.Itoa(end))
+	for i := start; i < end; i++ {
+		owner := commits[owners[i]]
+		fmt.Printf("%s (%-*s %s %*d) %s\n", shortHash(owners[i]), committerWidth, owner.Committer,
+			owner.Date.Format("2006-01-02 15:04:05 -0700"), numberWidth, i+1, strings.TrimSuffix(lines[i], "\n"))
+	}
+
+	return nil
+}
+
+// blameLines returns hash of the commit introducing each line of the file in given commit.
+// lines are passed down to parents which have them, starting with a parent having the same
+// blob, and lines no parent has are owned by the commit. commits are visited children first
+// so that a line reaching a commit through several children is resolved once.
+func (cs commitService) blameLines(path string, commitHash string, commits map[string]Commit, lineCount int) ([]string, error) {
+	owners := make([]string, lineCount)
+	contents := make(map[string][]string)
+	load := func(blobHash string) ([]string, error) {
+		if _, ok := contents[blobHash]; !ok {
+			lines, err := cs.ExtractFileFromObjectStore(blobHash)
+			if err != nil {
+				return nil, err
+			}
+			contents[blobHash] = lines
+		}
+		return contents[blobHash], nil
+	}
+
+	// pending maps a commit to the final lines each of its own lines stands for.
+	pending := map[string][][]int{commitHash: make([][]int, lineCount)}
+	for i := range pending[commitHash] {
+		pending[commitHash][i] = []int{i}
+	}
+
+	order, err := orderLogCommits([]string{commitHash}, commits)
+	if err != nil {
+		return nil, err
+	}
+	for _, hash := range order {
+		positions, ok := pending[hash]
+		if !ok {
+			continue
+		}
+		delete(pending, hash)
+
+		commit := commits[hash]
+		blobHash := commit.GetAllFilePaths()[path]
+		for _, parent := range blameParents(path, blobHash, commit, commits) {
+			parentBlobHash := commits[parent].GetAllFilePaths()[path]
+			parentLines, err := load(parentBlobHash)
+			if err != nil {
+				return nil, err
+			}
+
+			var matches []int
+			if parentBlobHash == blobHash {
+				matches = make([]int, len(parentLines))
+				for i := range matches {
+					matches[i] = i
+				}
+			} else {
+				blobLines, err := load(blobHash)
+				if err != nil {
+					return nil, err
+				}
+				matches = cs.myers.MatchLines(parentLines, blobLines)
+			}
+
+			parentPositions, ok := pending[parent]
+			if !ok {
+				parentPositions = make([][]int, len(parentLines))
+			}
+			for i, match := range matches {
+				if match != -1 && len(positions[i]) > 0 {
+					parentPositions[match] = append(parentPositions[match], positions[i]...)
+					positions[i] = nil
+				}
+			}
+			pending[parent] = parentPositions
+		}
+
+		for _, finals := range positions {
+			for _, final := range finals {
+				owners[final] = hash
+			}
+		}
+	}
+
+	return owners, nil
+}
+
+// blameParents returns parents of a commit which have given path, a parent with the same
+// blob comes first since all lines are passed to it unchanged.
+func blameParents(path string, blobHash string, commit Commit, commits map[string]Commit) []string {
+	parents := make([]string, 0)
+	for _, parent := range commit.GetParents() {
+		parentBlobHash, ok := commits[parent].GetAllFilePaths()[path]
+		if !ok {
+			continue
+		}
+		if parentBlobHash == blobHash {
+			parents = append([]string{parent}, parents...)
+		} else {
+			parents = append(parents, parent)
+		}
+	}
+
+	return parents
+}
+
+// parseLineRange converts a 1-based inclusive range like 10,20, 10,+5, 10, or ,20 into
+// slice bounds of a file with given number of lines.
+func parseLineRange(lineRange string, lineCount int) (int, int, error) {
+	if lineRange == "" {
+		return 0, lineCount, nil
+	}
+
+	startValue, endValue, found := strings.Cut(lineRange, ",")
+	if !found {
+		return 0, 0, errors.New("invalid line range, expected start,end: " + lineRange)
+	}
+
+	start := 1
+	if startValue != "" {
+		n, err := strconv.Atoi(startValue)
+		if err != nil || n < 1 {
+			return 0, 0, errors.New("invalid line range start: " + lineRange)
+		}
+		start = n
+	}
+
+	end := lineCount
+	if count, relative := strings.CutPrefix(endValue, "+"); relative {
+		n, err := strconv.Atoi(count)
+		if err != nil || n < 1 {
+			return 0, 0, errors.New("invalid line range end: " + lineRange)
+		}
+		end = start + n - 1
+	} else if endValue != "" {
+		n, err := strconv.Atoi(endValue)
+		if err != nil || n < start {
+			return 0, 0, errors.New("invalid line range end: " + lineRange)
+		}
+		end = n
+	}
+
+	if start > lineCount {
+		return 0, 0, fmt.Errorf("file has only %d lines", lineCount)
+	}
+
+	return start - 1, min(end, lineCount), nil
+}
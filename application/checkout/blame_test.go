@@ -0,0 +1,47 @@
+package checkout
+
+import "testing"
+
+func TestParseLineRange(t *testing.T) {
+	tests := []struct {
+		lineRange string
+		lineCount int
+		wantStart int
+		wantEnd   int
+		wantErr   bool
+	}{
+		{lineRange: "", lineCount: 10, wantStart: 0, wantEnd: 10},
+		{lineRange: "3,5", lineCount: 10, wantStart: 2, wantEnd: 5},
+		{lineRange: "3,+2", lineCount: 10, wantStart: 2, wantEnd: 4},
+		{lineRange: "3,", lineCount: 10, wantStart: 2, wantEnd: 10},
+		{lineRange: ",4", lineCount: 10, wantStart: 0, wantEnd: 4},
+		{lineRange: "8,20", lineCount: 10, wantStart: 7, wantEnd: 10},
+		{lineRange: "8,+5", lineCount: 10, wantStart: 7, wantEnd: 10},
+		{lineRange: "5,5", lineCount: 10, wantStart: 4, wantEnd: 5},
+		{lineRange: "5", lineCount: 10, wantErr: true},
+		{lineRange: "0,5", lineCount: 10, wantErr: true},
+		{lineRange: "x,5", lineCount: 10, wantErr: true},
+		{lineRange: "5,4", lineCount: 10, wantErr: true},
+		{lineRange: "5,+0", lineCount: 10, wantErr: true},
+		{lineRange: "5,+x", lineCount: 10, wantErr: true},
+		{lineRange: "11,12", lineCount: 10, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.lineRange, func(t *testing.T) {
+			start, end, err := parseLineRange(tt.lineRange, tt.lineCount)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseLineRange(%q, %d) = %d, %d, want an error", tt.lineRange, tt.lineCount, start, end)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseLineRange(%q, %d) returned error: %v", tt.lineRange, tt.lineCount, err)
+			}
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("parseLineRange(%q, %d) = %d, %d, want %d, %d", tt.lineRange, tt.lineCount, start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package checkout
+
+import (
+	"errors"
+	"fmt"
+	"git-light/util"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var cherryPickOperation = pickOperation{name: "cherry-pick", headFile: util.CherryPickHead}
+
+// sequencer files keep commits left to cherry-pick and the commit HEAD was at before
+// cherry-pick started, so that a stopped cherry-pick can be continued or aborted.
+var (
+	sequencerTodoPath = filepath.Join(util.BaseFilePath, util.SequencerFolder, "todo")
+	sequencerHeadPath = filepath.Join(util.BaseFilePath, util.SequencerFolder, "head")
+)
+
+// CherryPick applies changes of given commits on top of current branch in order, ranges
+// A..B pick commits reachable from B but not from A oldest first. picked commits keep their
+// message and committer and a trailer naming the original commit is appended.
+func (cs commitService) CherryPick(revisions []string) error {
+	if _, err := os.Stat(sequencerTodoPath); err == nil {
+		return errors.New("a cherry-pick is in progress. continue or abort it before cherry-picking")
+	}
+	err := cs.requireCleanTree("cherry-picking")
+	if err != nil {
+		return err
+	}
+
+	hashes := make([]string, 0)
+	for _, rev := range revisions {
+		if !cs.revisions.IsRange(rev) {
+			commitHash, err := cs.revisions.Resolve(rev)
+			if err != nil {
+				return err
+			}
+			hashes = append(hashes, commitHash)
+			continue
+		}
+
+		from, to, err := cs.revisions.ResolveRange(rev)
+		if err != nil {
+			return err
+		}
+		excluded, err := cs.collectAncestors(from)
+		if err != nil {
+			return err
+		}
+		commits, err := cs.collectLogCommits([]string{to}, excluded)
+		if err != nil {
+			return err
+		}
+		order, err := orderLogCommits([]string{to}, commits)
+		if err != nil {
+			return err
+		}
+		slices.Reverse(order)
+		hashes = append(hashes, order...)
+	}
+	for _, hash := range hashes {
+		commit, err := cs.findCommit(hash)
+		if err != nil {
+			return err
+		}
+		if len(commit.GetParents()) > 1 {
+			return errors.New("commit " + hash + " is a merge, cherry-picking merges is not supported")
+		}
+	}
+
+	head, err := cs.readHead()
+	if err != nil {
+		return err
+	}
+	err = cs.repo.WriteToFile(sequencerHeadPath, []string{head.Hash})
+	if err != nil {
+		return fmt.Errorf("failed to save cherry-pick state: %w", err)
+	}
+
+	return cs.runCherryPicks(hashes)
+}
+
+// ContinueCherryPick commits the resolved conflict of a stopped cherry-pick and picks the
+// remaining commits.
+func (cs commitService) ContinueCherryPick() error {
+	todo, err := cs.repo.GetFileLines(sequencerTodoPath)
+	if err != nil {
+		return errors.New("there is no cherry-pick in progress")
+	}
+
+	if cherryPickOperation.inProgress() {
+		headLines, err := cs.repo.GetFileLines(filepath.Join(util.BaseFilePath, util.CherryPickHead))
+		if err != nil || len(headLines) == 0 {
+			return errors.New("failed to read cherry-pick state")
+		}
+		picked, err := cs.findCommit(headLines[0])
+		if err != nil {
+			return err
+		}
+		err = cs.continuePick(cherryPickOperation, picked.Committer)
+		if err != nil {
+			return err
+		}
+	}
+
+	return cs.runCherryPicks(todo)
+}
+
+// AbortCherryPick drops a stopped cherry-pick and moves current branch back to where it
+// was before cherry-pick started.
+func (cs commitService) AbortCherryPick() error {
+	headLines, err := cs.repo.GetFileLines(sequencerHeadPath)
+	if err != nil || len(headLines) == 0 {
+		return errors.New("there is no cherry-pick in progress")
+	}
+
+	if cherryPickOperation.inProgress() {
+		err = cs.abortPick(cherryPickOperation)
+		if err != nil {
+			return err
+		}
+	}
+	head, err := cs.readHead()
+	if err != nil {
+		return err
+	}
+	if head.Hash != headLines[0] {
+		err = cs.Reset(headLines[0], ResetHard)
+		if err != nil {
+			return err
+		}
+	}
+
+	return cs.clearSequencer()
+}
+
+// runCherryPicks picks given commits one by one, when one of them stops for conflicts the
+// rest are saved for ContinueCherryPick.
+func (cs commitService) runCherryPicks(hashes []string) error {
+	for i, hash := range hashes {
+		err := cs.repo.WriteToFile(sequencerTodoPath, hashes[i+1:])
+		if err != nil {
+			return fmt.Errorf("failed to save cherry-pick state: %w", err)
+		}
+
+		picked, err := cs.findCommit(hash)
+		if err != nil {
+			return err
+		}
+		parent, err := cs.firstParent(picked)
+		if err != nil {
+			return err
+		}
+
+		subject, _, _ := strings.Cut(picked.Message, "\n")
+		message := picked.Message + "\n\n(cherry picked from commit " + hash + ")"
+		err = cs.applyPick(cherryPickOperation, hash, parent, picked, shortHash(hash)+" ("+subject+")", message, picked.Committer)
+		if err != nil && !cherryPickOperation.inProgress() {
+			// nothing was applied, keep the commit in todo so that continue picks it again.
+			if saveErr := cs.repo.WriteToFile(sequencerTodoPath, hashes[i:]); saveErr != nil {
+				return fmt.Errorf("failed to save cherry-pick state: %w", saveErr)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Println("picked " + shortHash(hash) + " " + subject)
+	}
+
+	return cs.clearSequencer()
+}
+
+func (cs commitService) clearSequencer() error {
+	err := os.RemoveAll(filepath.Join(util.BaseFilePath, util.SequencerFolder))
+	if err != nil {
+		return fmt.Errorf("failed to clear cherry-pick state: %w", err)
+	}
+
+	return nil
+}
@@ -1,32 +1,82 @@
 package checkout
 
 import (
+	"bytes"
 	"crypto/sha1"
 	"encoding/hex"
-	"log"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
+const (
+	// LegacyCommitVersion commits are hashed over their file hashes only.
+	LegacyCommitVersion = 0
+	// CurrentCommitVersion commits are hashed over canonical serialization of whole commit.
+	CurrentCommitVersion = 1
+)
+
 type Commit struct {
+	Version        int
 	Committer      string
 	Date           time.Time
 	PreviousCommit string
+	Parents        []string
 	Message        string
 	Files          []File
 }
 
 type File struct {
-	Path string
-	Hash string
+	Path   string
+	Hash   string
+	Binary bool
 }
 
 func (c Commit) CalculateHashForCommit() string {
+	if c.Version == LegacyCommitVersion {
+		return c.calculateLegacyHash()
+	}
+
+	serialized := c.Serialize()
+	hasher := sha1.New()
+	hasher.Write([]byte("commit " + strconv.Itoa(len(serialized)) + "\x00"))
+	hasher.Write(serialized)
+
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// Serialize returns canonical form of the commit which is used for hashing. files are
+// sorted by path and free form fields are length prefixed so that no two different
+// commits share the same serialization.
+func (c Commit) Serialize() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("version " + strconv.Itoa(c.Version) + "\n")
+
+	files := slices.Clone(c.Files)
+	slices.SortFunc(files, func(a, b File) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+	for _, file := range files {
+		buf.WriteString("tree " + strconv.Itoa(len(file.Path)) + " " + file.Path + " " + file.Hash + "\n")
+	}
+
+	for _, parent := range c.GetParents() {
+		buf.WriteString("parent " + parent + "\n")
+	}
+
+	buf.WriteString("committer " + strconv.Itoa(len(c.Committer)) + " " + c.Committer + "\n")
+	buf.WriteString("date " + strconv.FormatInt(c.Date.UnixNano(), 10) + "\n")
+	buf.WriteString("message " + strconv.Itoa(len(c.Message)) + "\n" + c.Message)
+
+	return buf.Bytes()
+}
+
+func (c Commit) calculateLegacyHash() string {
 	hasher := sha1.New()
 	for _, file := range c.Files {
-		_, err := hasher.Write([]byte(file.Hash))
-		if err != nil {
-			log.Fatal("got error while hashing commit, err: ", err.Error())
-		}
+		hasher.Write([]byte(file.Hash))
 	}
 
 	hashSum := hasher.Sum(nil)
@@ -35,6 +85,19 @@ func (c Commit) CalculateHashForCommit() string {
 	return hashString
 }
 
+// GetParents returns parent commit hashes, commits created before merge support
+// only have PreviousCommit set so it is used as the single parent for them.
+func (c Commit) GetParents() []string {
+	if len(c.Parents) > 0 {
+		return c.Parents
+	}
+	if c.PreviousCommit == "" || c.PreviousCommit == "nil" {
+		return []string{}
+	}
+
+	return []string{c.PreviousCommit}
+}
+
 func (c Commit) GetAllFilePaths() map[string]string {
 	filePaths := make(map[string]string)
 	for _, file := range c.Files {
@@ -44,6 +107,53 @@ func (c Commit) GetAllFilePaths() map[string]string {
 	return filePaths
 }
 
+// GetFile returns tree entry of given path, empty entry is returned if path is not tracked.
+func (c Commit) GetFile(path string) File {
+	for _, file := range c.Files {
+		if file.Path == path {
+			return file
+		}
+	}
+
+	return File{}
+}
+
+// SetFile puts given entry into the tree, entry with the same path is replaced if any.
+func (c *Commit) SetFile(file File) {
+	for i := range c.Files {
+		if c.Files[i].Path == file.Path {
+			c.Files[i] = file
+			return
+		}
+	}
+
+	c.Files = append(c.Files, file)
+}
+
+// RemoveFile drops entry of given path from the tree and reports whether it was tracked.
+func (c *Commit) RemoveFile(path string) bool {
+	for i := range c.Files {
+		if c.Files[i].Path == path {
+			c.Files = slices.Delete(c.Files, i, i+1)
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetFilesUnder returns tracked paths which are given path itself or inside of it.
+func (c Commit) GetFilesUnder(path string) []string {
+	filePaths := make([]string, 0)
+	for _, file := range c.Files {
+		if path == "." || file.Path == path || strings.HasPrefix(file.Path, path+string(filepath.Separator)) {
+			filePaths = append(filePaths, file.Path)
+		}
+	}
+
+	return filePaths
+}
+
 func (c Commit) GetFilePathList() []string {
 	filePaths := make([]string, 0)
 	for _, file := range c.Files {
@@ -1,14 +1,21 @@
 package checkout
 
 import (
+	"bytes"
 	"crypto/sha1"
 	"encoding/hex"
 	"errors"
 	"fmt"
+	"git-light/application/attributes"
+	"git-light/application/hook"
+	"git-light/application/ignore"
 	"git-light/application/myersdiff"
+	"git-light/application/ref"
 	"git-light/application/repository"
+	"git-light/application/revision"
 	"git-light/util"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
 	"slices"
@@ -18,303 +25,539 @@ import (
 )
 
 type CommitService interface {
-	Initialize()
-	AddToStage(filePaths []string)
-	CommitChanges(commitMessage string, committer string)
-	Checkout(commitHash string)
-	Log()
+	Initialize() error
+	AddToStage(filePaths []string) error
+	CommitChanges(commitMessage string, committer string) error
+	Remove(filePaths []string, cached bool, force bool) error
+	Move(source string, destination string, force bool) error
+	Checkout(commitHash string, force bool) error
+	Log(options LogOptions) error
+	Status() ([]FileStatus, error)
+	Diff(revisions []string, staged bool, contextLines int) error
+	Merge(branchName string, committer string) error
+	AbortMerge() error
+	Migrate() error
+	Reflog(refName string) error
+	Show(object string, contextLines int) error
+	Blame(path string, rev string, lineRange string) error
+	Reset(rev string, mode ResetMode) error
+	Revert(rev string, committer string) error
+	ContinueRevert(committer string) error
+	AbortRevert() error
+	CherryPick(revisions []string) error
+	ContinueCherryPick() error
+	AbortCherryPick() error
+	Rebase(upstream string, todoFile string) error
+	ContinueRebase() error
+	SkipRebase() error
+	AbortRebase() error
+	StashPush(message string, committer string) error
+	StashList() error
+	StashApply(entry string) error
+	StashPop(entry string) error
+	StashDrop(entry string) error
+	ResetPaths(rev string, paths []string) error
 }
 
+// errEmptyBranch is returned for the last commit of a branch which has no commits yet.
+var errEmptyBranch = errors.New("empty branch")
+
 type commitService struct {
-	repo  repository.Repository
-	myers myersdiff.Myers
+	repo       repository.Repository
+	myers      myersdiff.Myers
+	hooks      hook.HookRunner
+	attributes attributes.AttributeResolver
+	ignore     ignore.IgnoreMatcher
+	refs       ref.RefStore
+	revisions  revision.RevisionResolver
 }
 
-func NewCommitService(repo repository.Repository, myers myersdiff.Myers) CommitService {
-	return commitService{repo: repo, myers: myers}
+func NewCommitService(repo repository.Repository, myers myersdiff.Myers, hooks hook.HookRunner, attributes attributes.AttributeResolver, ignore ignore.IgnoreMatcher, refs ref.RefStore, revisions revision.RevisionResolver) CommitService {
+	return commitService{repo: repo, myers: myers, hooks: hooks, attributes: attributes, ignore: ignore, refs: refs, revisions: revisions}
 }
 
-func (cs commitService) Initialize() {
-	if cs.checkObjectStore() {
-		log.Fatal("this directory has already initialized with git-light")
-	}
-
-	err := os.Mkdir(util.BaseFilePath, 0700)
+func (cs commitService) Initialize() error {
+	initialized, err := cs.checkObjectStore()
 	if err != nil {
-		log.Fatal(err)
+		return err
+	}
+	if initialized {
+		return errors.New("already a git-light repository, " + util.BaseFilePath + " exists in this directory")
 	}
 
-	err = os.Mkdir(filepath.Join(util.BaseFilePath, util.BranchFolder), 0700)
-	if err != nil {
-		log.Fatal(err)
+	folders := []string{"", util.BranchFolder, util.StageFolder, util.TagFolder, util.ObjectFolder, util.TempFolder, util.HookFolder}
+	for _, folder := range folders {
+		err = os.Mkdir(filepath.Join(util.BaseFilePath, folder), 0700)
+		if err != nil {
+			return err
+		}
 	}
 
-	err = os.Mkdir(filepath.Join(util.BaseFilePath, util.StageFolder), 0700)
+	err = cs.refs.WriteBranch(util.DefaultBranchName, "nil", "")
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	err = os.Mkdir(filepath.Join(util.BaseFilePath, util.ObjectFolder), 0700)
+	return cs.refs.AttachHead(util.DefaultBranchName, "")
+}
+
+func (cs commitService) CommitChanges(commitMessage string, committer string) error {
+	head, err := cs.readHead()
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	err = os.Mkdir(filepath.Join(util.BaseFilePath, util.TempFolder), 0700)
+	var commit Commit
+	err = cs.repo.DecompressFromFileAndConvert(filepath.Join(util.BaseFilePath, util.StageFolder, "commit"), &commit)
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("%w, you should first add your changes", util.ErrNothingStaged)
 	}
-
-	err = cs.repo.WriteToFile(filepath.Join(util.BaseFilePath, util.Head), []string{util.DefaultBranchName})
+	err = cs.requireMerged("committing")
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
-	err = cs.repo.WriteToFile(filepath.Join(util.BaseFilePath, util.BranchFolder, util.DefaultBranchName), []string{"nil"})
+	env := cs.hookEnv(head, commit.PreviousCommit)
+	err = cs.hooks.Run(hook.PreCommit, []string{}, env)
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("pre-commit hook failed, aborting commit process: %w", err)
 	}
-}
 
-func (cs commitService) CommitChanges(commitMessage string, committer string) {
-	var commit Commit
-	err := cs.repo.DecompressFromFileAndConvert(filepath.Join(util.BaseFilePath, util.StageFolder, "commit"), &commit)
+	commit.Message, err = cs.runCommitMsgHook(commitMessage, env)
 	if err != nil {
-		log.Fatal("nothing found in staging area, you should first add your changes")
+		return err
 	}
-
-	commit.Message = commitMessage
 	commit.Committer = committer
 	commit.Date = time.Now()
+	commit.Version = CurrentCommitVersion
+	commit.Parents = commit.GetParents()
+
+	reason := "commit: "
+	mergeHead, err := cs.repo.GetFileLines(filepath.Join(util.BaseFilePath, util.MergeHead))
+	if err == nil {
+		commit.Parents = []string{commit.PreviousCommit, mergeHead[0]}
+		reason = "commit (merge): "
+	} else if commit.PreviousCommit == "nil" {
+		reason = "commit (initial): "
+	} else {
+		previousCommit, err := cs.findCommit(commit.PreviousCommit)
+		if err != nil {
+			return err
+		}
+		if maps.Equal(previousCommit.GetAllFilePaths(), commit.GetAllFilePaths()) {
+			return fmt.Errorf("%w, no change has been made since last commit", util.ErrNothingStaged)
+		}
+	}
+
 	err = cs.repo.CompressAndSaveToFile(commit, filepath.Join(util.BaseFilePath, util.StageFolder, "commit"))
 	if err != nil {
-		log.Fatal("failed to write commit object from staging area")
+		return fmt.Errorf("failed to write commit object from staging area: %w", err)
 	}
 
 	commitHash := commit.CalculateHashForCommit()
-	if commitHash == commit.PreviousCommit {
-		log.Fatal("no change has been made since last commit. aborting commit process.")
+	if _, err := os.Stat(filepath.Join(util.BaseFilePath, util.ObjectFolder, commitHash)); err == nil {
+		return errors.New("a commit with the same content already exists: " + commitHash + ". aborting commit process")
 	}
 	err = os.Rename(filepath.Join(util.BaseFilePath, util.StageFolder, "commit"), filepath.Join(util.BaseFilePath, util.StageFolder, commitHash))
 	if err != nil {
-		log.Fatal("failed to rename commit object from staging area")
+		return fmt.Errorf("failed to rename commit object from staging area: %w", err)
 	}
 
-	cs.UpdateCurrentBranch(commitHash)
+	firstLine, _, _ := strings.Cut(commit.Message, "\n")
+	err = cs.refs.WithIdentity(committer).UpdateHead(commitHash, reason+firstLine)
+	if err != nil {
+		return fmt.Errorf("failed to update HEAD: %w", err)
+	}
 
 	err = cs.repo.MoveFiles(filepath.Join(util.BaseFilePath, util.StageFolder), filepath.Join(util.BaseFilePath, util.ObjectFolder))
 	if err != nil {
-		log.Fatal("failed to move staging area to permanent object store")
+		return fmt.Errorf("failed to move staging area to permanent object store: %w", err)
+	}
+
+	if len(commit.Parents) > 1 {
+		err = cs.repo.DeleteFiles(filepath.Join(util.BaseFilePath, util.MergeHead))
+		if err != nil {
+			return fmt.Errorf("failed to clear merge state: %w", err)
+		}
+	}
+	err = cs.clearPickState()
+	if err != nil {
+		return err
+	}
+
+	err = cs.hooks.Run(hook.PostCommit, []string{}, cs.hookEnv(head, commitHash))
+	if err != nil {
+		log.Println("post-commit hook failed. err: " + err.Error())
 	}
+
+	return nil
 }
 
-func (cs commitService) AddToStage(filePaths []string) {
-	var canCommitBeCreated = false
-	stageCommit := Commit{
-		Committer:      "",
-		Date:           time.Time{},
-		PreviousCommit: "",
-		Message:        "",
-		Files:          make([]File, 0),
+// AddToStage stages content of given files. tracked files which are missing in working
+// directory are staged as deleted, directories stand for all files under them. added
+// paths are no longer unmerged.
+func (cs commitService) AddToStage(filePaths []string) error {
+	stageCommit, err := cs.loadStage()
+	if err != nil {
+		return err
 	}
 
-	lastCommit, err := cs.GetLastCommitOnCurrentBranch()
+	expandedPaths, err := cs.expandPaths(filePaths, stageCommit)
 	if err != nil {
-		stageCommit.PreviousCommit = "nil"
-		for _, filePath := range filePaths {
-			lines, err := cs.repo.GetFileLines(filePath)
+		return err
+	}
+	resolved := make([]string, 0, len(expandedPaths))
+	for _, path := range expandedPaths {
+		content, err := cs.readWorkingFile(path)
+		if err != nil {
+			continue
+		}
+		resolved = append(resolved, path)
+
+		previousFile := stageCommit.GetFile(path)
+		previousHash := "nil"
+		if previousFile.Hash != "" {
+			matches, err := cs.blobMatches(previousFile.Hash, content)
 			if err != nil {
-				log.Fatal("failed to read files. file path: " + filePath)
+				return err
 			}
-			canCommitBeCreated = true
-			sha1Hash := cs.CalculateSHA1Hash(lines)
-			stageCommit.Files = append(stageCommit.Files, File{Path: filePath, Hash: sha1Hash})
-			err = cs.repo.CompressAndSaveToFile(myersdiff.Diff{PreviousBlobHash: "nil", Commands: "nil", Data: lines}, filepath.Join(util.BaseFilePath, util.StageFolder, sha1Hash))
-			if err != nil {
-				log.Fatal("failed to save given file to stage: " + filePath)
+			if matches {
+				continue
 			}
+			previousHash = previousFile.Hash
 		}
-	} else {
-		stageCommit.PreviousCommit = lastCommit.CalculateHashForCommit()
-		lastCommitFilePathList := lastCommit.GetFilePathList()
-		allPathsCombined := append(filePaths, lastCommitFilePathList...)
-		for _, path := range allPathsCombined {
-			currentFile, err := cs.repo.GetFileLines(path)
-			if err != nil && !slices.Contains(lastCommitFilePathList, path) {
+
+		file, err := cs.stageFileContent(path, content, previousHash)
+		if err != nil {
+			return err
+		}
+		stageCommit.SetFile(file)
+	}
+
+	for _, path := range filePaths {
+		path = filepath.Clean(path)
+		var matched = false
+		for _, trackedPath := range stageCommit.GetFilesUnder(path) {
+			if _, err := os.Stat(trackedPath); os.IsNotExist(err) {
+				stageCommit.RemoveFile(trackedPath)
+				resolved = append(resolved, trackedPath)
+			}
+			matched = true
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			resolved = append(resolved, path)
+			if !matched {
 				log.Println("file couldn't found on working directory, filepath: " + path)
-			} else if err != nil && slices.Contains(lastCommitFilePathList, path) && slices.Contains(filePaths, path) {
-				canCommitBeCreated = true
-			} else if err != nil && slices.Contains(lastCommitFilePathList, path) && !slices.Contains(filePaths, path) {
-				sha1Hash := cs.CalculateSHA1Hash(currentFile)
-				stageCommit.Files = append(stageCommit.Files, File{Path: path, Hash: sha1Hash})
-			} else if err == nil && !slices.Contains(lastCommitFilePathList, path) {
-				canCommitBeCreated = true
-				sha1Hash := cs.CalculateSHA1Hash(currentFile)
-				stageCommit.Files = append(stageCommit.Files, File{Path: path, Hash: sha1Hash})
-				err = cs.repo.CompressAndSaveToFile(myersdiff.Diff{PreviousBlobHash: "nil", Commands: "nil", Data: currentFile}, filepath.Join(util.BaseFilePath, util.StageFolder, sha1Hash))
-				if err != nil {
-					log.Fatal("failed to save given file to stage: " + path)
-				}
-			} else {
-				previousFile := cs.ExtractFileFromObjectStore(lastCommit.GetAllFilePaths()[path])
-				currentFileHash := cs.CalculateSHA1Hash(currentFile)
-				previousFileHash := cs.CalculateSHA1Hash(previousFile)
-				if currentFileHash != previousFileHash {
-					if !slices.Contains(stageCommit.GetFilePathList(), path) {
-						stageCommit.Files = append(stageCommit.Files, File{Path: path, Hash: currentFileHash})
-					}
-					canCommitBeCreated = true
-					diff := cs.myers.GenerateDiffScript(previousFile, currentFile)
-					diff.PreviousBlobHash = previousFileHash
-					err := cs.repo.CompressAndSaveToFile(diff, filepath.Join(util.BaseFilePath, util.StageFolder, currentFileHash))
-					if err != nil {
-						log.Fatal("failed to save given file to stage: " + path)
-					}
-				}
 			}
 		}
 	}
 
-	if canCommitBeCreated {
-		err = cs.repo.CompressAndSaveToFile(stageCommit, filepath.Join(util.BaseFilePath, util.StageFolder, "commit"))
-		if err != nil {
-			log.Fatal("failed to save commit to stage")
-		}
+	err = cs.saveStage(stageCommit)
+	if err != nil {
+		return err
 	}
+
+	return cs.resolveUnmergedPaths(resolved)
 }
 
-func (cs commitService) Checkout(commitHashOrBranch string) {
-	var commit Commit
+// Checkout moves working directory from current commit to given commit or branch. only
+// files which differ between both commits are touched and untracked files are left alone.
+// checkout is refused when local changes would be lost, unless force is set.
+func (cs commitService) Checkout(commitHashOrBranch string, force bool) error {
+	previousHead, err := cs.readHead()
+	if err != nil {
+		return err
+	}
+	previousCommitHash := previousHead.Hash
+	reason := "checkout: moving from " + previousHead.Branch + " to " + commitHashOrBranch
+	if previousHead.IsDetached() {
+		reason = "checkout: moving from " + previousCommitHash + " to " + commitHashOrBranch
+	}
+	targetBranch := ""
+	branchCheckout := "0"
 
-	if strings.HasPrefix(commitHashOrBranch, "HEAD~") {
-		commit, err := cs.GetLastCommitOnCurrentBranch()
+	if commitHash, err := cs.refs.ReadBranch(commitHashOrBranch); err == nil {
+		targetBranch = commitHashOrBranch
+		commitHashOrBranch = commitHash
+		branchCheckout = "1"
+	} else {
+		commitHashOrBranch, err = cs.revisions.Resolve(commitHashOrBranch)
 		if err != nil {
-			log.Fatal("failed to get last commit on current branch when getting previous commit")
+			return err
 		}
+	}
 
-		numberOfCommits, err := strconv.Atoi(strings.TrimPrefix(commitHashOrBranch, "HEAD~"))
+	var target Commit
+	if commitHashOrBranch != "nil" {
+		target, err = cs.findCommit(commitHashOrBranch)
 		if err != nil {
-			log.Fatal("failed to extract number of commits to go back from given HEAD~ pattern")
+			return err
 		}
+	}
 
-		commitHashOrBranch = cs.getPreviousCommit(commit.PreviousCommit, numberOfCommits-1)
-	} else {
-		lines, err := cs.repo.GetFileLines(filepath.Join(util.BaseFilePath, util.BranchFolder, commitHashOrBranch))
-		if err == nil {
-			err := cs.repo.WriteToFile(filepath.Join(util.BaseFilePath, util.Head), []string{commitHashOrBranch})
-			if err != nil {
-				log.Fatal("an error occurred when updating head")
-			}
-			commitHashOrBranch = lines[0]
-		} else {
-			err := cs.repo.WriteToFile(filepath.Join(util.BaseFilePath, util.Head), []string{commitHashOrBranch})
-			if err != nil {
-				log.Fatal("an error occurred when updating head")
-			}
+	var current Commit
+	if previousCommitHash != "nil" {
+		current, err = cs.findCommit(previousCommitHash)
+		if err != nil {
+			return err
+		}
+	}
+	stagedCommit, stagedErr := cs.GetStagedCommit()
+	if stagedErr != nil {
+		stagedCommit = current
+	}
+
+	if !force {
+		if cs.isMerging() {
+			return errors.New("a merge is in progress. commit the result or abort it before checkout, or use --force")
+		}
+
+		conflicts, err := cs.checkoutConflicts(current.GetAllFilePaths(), stagedCommit.GetAllFilePaths(), target.GetAllFilePaths())
+		if err != nil {
+			return err
+		}
+		if len(conflicts) > 0 {
+			return util.ConflictError{Message: "your local changes to the following files would be overwritten by checkout. commit them or use --force to discard them", Paths: conflicts}
 		}
 	}
 
-	err := cs.repo.DecompressFromFileAndConvert(filepath.Join(util.BaseFilePath, util.ObjectFolder, commitHashOrBranch), &commit)
+	removedFiles := current.GetAllFilePaths()
+	if force {
+		maps.Copy(removedFiles, stagedCommit.GetAllFilePaths())
+	}
+	err = cs.checkoutTree(removedFiles, target.GetAllFilePaths(), force)
 	if err != nil {
-		log.Fatal("no such commit hash / branch found")
+		return err
 	}
 
-	var checkoutFailure = false
-	for _, file := range commit.Files {
-		content := cs.ExtractFileFromObjectStore(file.Hash)
+	if targetBranch != "" {
+		err = cs.refs.AttachHead(targetBranch, reason)
+	} else {
+		err = cs.refs.DetachHead(commitHashOrBranch, reason)
+	}
+	if err != nil {
+		return fmt.Errorf("an error occurred when updating head: %w", err)
+	}
 
-		err = cs.repo.WriteToFile(filepath.Join(util.BaseFilePath, util.TempFolder, file.Path), content)
+	if previousHead.IsDetached() && previousCommitHash != commitHashOrBranch {
+		err = cs.warnUnreachable(previousCommitHash)
 		if err != nil {
-			log.Println("failed to write file to object store with name: " + file.Path)
-			checkoutFailure = true
-			break
+			return err
 		}
 	}
+	if targetBranch == "" {
+		fmt.Println("HEAD is now detached at " + commitHashOrBranch)
+	}
 
-	if checkoutFailure {
-		err = os.Remove(filepath.Join(util.BaseFilePath, util.TempFolder))
+	if force {
+		err = cs.clearStage()
+		if err != nil {
+			return err
+		}
+		err = cs.repo.DeleteFiles(filepath.Join(util.BaseFilePath, util.MergeHead))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clear merge state: %w", err)
+		}
+	} else if stagedErr == nil {
+		err = cs.carryStagedChanges(current, stagedCommit, target, commitHashOrBranch)
 		if err != nil {
-			log.Fatal("failed to remove temporary files")
+			return err
 		}
 	}
 
-	err = cs.repo.MoveFiles(filepath.Join(util.BaseFilePath, util.TempFolder), "./")
+	err = cs.hooks.Run(hook.PostCheckout, []string{previousCommitHash, commitHashOrBranch, branchCheckout}, cs.hookEnv(ref.Head{Branch: targetBranch}, commitHashOrBranch))
 	if err != nil {
-		log.Fatal("failed to move extracted files")
+		log.Println("post-checkout hook failed. err: " + err.Error())
 	}
+
+	return nil
 }
 
-func (cs commitService) checkObjectStore() bool {
-	currentDir, err := filepath.Abs(filepath.Dir(os.Args[0]))
-	if err != nil {
-		log.Fatal(err)
+// checkObjectStore reports whether working directory is a repository already.
+func (cs commitService) checkObjectStore() (bool, error) {
+	_, err := os.Stat(util.BaseFilePath)
+	if os.IsNotExist(err) {
+		return false, nil
 	}
-	if _, err := os.Stat(filepath.Join(currentDir, util.BaseFilePath)); os.IsNotExist(err) {
-		return false
-	} else {
-		return true
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s: %w", util.BaseFilePath, err)
 	}
+
+	return true, nil
 }
 
-func (cs commitService) GetCurrentBranch() string {
-	lines, err := cs.repo.GetFileLines(filepath.Join(util.BaseFilePath, util.Head))
+func (cs commitService) readHead() (ref.Head, error) {
+	head, err := cs.refs.ReadHead()
 	if err != nil {
-		log.Fatal("failed to get current branch")
+		return ref.Head{}, fmt.Errorf("failed to read HEAD: %w", err)
 	}
-	return lines[0]
+
+	return head, nil
 }
 
-func (cs commitService) UpdateCurrentBranch(commitSha string) {
-	currentBranch := cs.GetCurrentBranch()
-	err := cs.repo.WriteToFile(filepath.Join(util.BaseFilePath, util.BranchFolder, currentBranch), []string{commitSha})
+// updateHead moves current branch to given commit, HEAD itself is moved when detached.
+// reason is recorded in the reflog.
+func (cs commitService) updateHead(commitHash string, reason string) error {
+	err := cs.refs.UpdateHead(commitHash, reason)
 	if err != nil {
-		log.Fatal("failed to update current branch")
+		return fmt.Errorf("failed to update HEAD: %w", err)
 	}
+
+	return nil
 }
 
+// GetLastCommitOnCurrentBranch returns the commit HEAD points to, errEmptyBranch is
+// returned when current branch doesn't have any commits yet.
 func (cs commitService) GetLastCommitOnCurrentBranch() (Commit, error) {
-	currentBranch := cs.GetCurrentBranch()
-	lines, err := cs.repo.GetFileLines(filepath.Join(util.BaseFilePath, util.BranchFolder, currentBranch))
+	head, err := cs.readHead()
 	if err != nil {
-		log.Fatal("failed to get last commit on current branch.")
+		return Commit{}, err
+	}
+	if head.Hash == "nil" {
+		return Commit{}, errEmptyBranch
 	}
 
-	if lines[0] == "nil" {
-		return Commit{}, errors.New("empty branch")
+	return cs.findCommit(head.Hash)
+}
+
+// findCommit loads commit object with given hash.
+func (cs commitService) findCommit(commitHash string) (Commit, error) {
+	var commit Commit
+	err := cs.repo.DecompressFromFileAndConvert(filepath.Join(util.BaseFilePath, util.ObjectFolder, commitHash), &commit)
+	if err != nil {
+		return Commit{}, errors.New("no such commit found: " + commitHash)
 	}
 
+	return commit, nil
+}
+
+func (cs commitService) GetStagedCommit() (Commit, error) {
 	var commit Commit
-	err = cs.repo.DecompressFromFileAndConvert(filepath.Join(util.BaseFilePath, util.ObjectFolder, lines[0]), &commit)
+	err := cs.repo.DecompressFromFileAndConvert(filepath.Join(util.BaseFilePath, util.StageFolder, "commit"), &commit)
 	if err != nil {
-		log.Fatal("failed to get last commit from repository")
+		return Commit{}, err
 	}
 
 	return commit, nil
 }
 
-func (cs commitService) ExtractFileFromObjectStore(hash string) []string {
-	var diff myersdiff.Diff
-	err := cs.repo.DecompressFromFileAndConvert(filepath.Join(util.BaseFilePath, util.ObjectFolder, hash), &diff)
+// ExtractContentFromObjectStore returns exact content of the blob with given hash.
+func (cs commitService) ExtractContentFromObjectStore(hash string) ([]byte, error) {
+	diff, err := cs.readBlob(hash)
+	if err != nil {
+		return nil, err
+	}
+	if diff.Binary {
+		return diff.Content, nil
+	}
+
+	lines, err := cs.ExtractFileFromObjectStore(hash)
+	if err != nil {
+		return nil, err
+	}
+
+	return joinLines(lines), nil
+}
+
+// ExtractFileFromObjectStore returns lines of the blob with given hash by applying its
+// chain of deltas, a chain leading back to one of its own blobs is reported as an error.
+func (cs commitService) ExtractFileFromObjectStore(hash string) ([]string, error) {
+	var chain []myersdiff.Diff
+	visited := make(map[string]bool)
+	for hash != "nil" {
+		if visited[hash] {
+			return nil, errors.New("delta chain of blob " + hash + " refers to itself, object store is corrupt")
+		}
+		visited[hash] = true
+
+		diff, err := cs.readBlob(hash)
+		if err != nil {
+			return nil, err
+		}
+		if diff.Binary {
+			diff = myersdiff.Diff{PreviousBlobHash: "nil", Data: splitLines(diff.Content), Exact: true}
+		}
+		if !diff.Exact {
+			for i := range diff.Data {
+				diff.Data[i] += "\n"
+			}
+		}
+		chain = append(chain, diff)
+		hash = diff.PreviousBlobHash
+	}
+
+	lines := chain[len(chain)-1].Data
+	for i := len(chain) - 2; i >= 0; i-- {
+		var err error
+		lines, err = cs.applyDelta(lines, chain[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return lines, nil
+}
 
+func (cs commitService) readBlob(hash string) (myersdiff.Diff, error) {
+	var diff myersdiff.Diff
+	err := cs.repo.DecompressFromFileAndConvert(cs.objectPath(hash), &diff)
 	if err != nil {
-		log.Fatal("failed to decompress delta err: " + err.Error())
+		return myersdiff.Diff{}, fmt.Errorf("failed to decompress delta %s: %w", hash, err)
+	}
+
+	return diff, nil
+}
+
+// stageFileContent saves content to staging area and returns tree entry for it. text files
+// are saved as a delta against their previous blob if there is one, binary files are saved
+// as a whole.
+func (cs commitService) stageFileContent(path string, content []byte, previousHash string) (File, error) {
+	hash := cs.CalculateBlobHash(content)
+	if isBinary(content) {
+		err := cs.saveBlobToStage(hash, myersdiff.Diff{PreviousBlobHash: "nil", Commands: "nil", Binary: true, Content: content})
+		return File{Path: path, Hash: hash, Binary: true}, err
+	}
+
+	lines := splitLines(content)
+	diff := myersdiff.Diff{PreviousBlobHash: "nil", Commands: "nil", Data: lines}
+	if previousHash != "nil" {
+		previous, err := cs.readBlob(previousHash)
+		if err != nil {
+			return File{}, err
+		}
+		if !previous.Binary {
+			previousLines, err := cs.ExtractFileFromObjectStore(previousHash)
+			if err != nil {
+				return File{}, err
+			}
+			diff = cs.myers.GenerateDiffScript(previousLines, lines)
+			diff.PreviousBlobHash = previousHash
+		}
 	}
+	diff.Exact = true
+
+	return File{Path: path, Hash: hash}, cs.saveBlobToStage(hash, diff)
+}
 
-	if diff.PreviousBlobHash == "nil" {
-		return diff.Data
+// objectPath returns location of given object, objects which are not committed yet
+// are served from staging area.
+func (cs commitService) objectPath(hash string) string {
+	path := filepath.Join(util.BaseFilePath, util.ObjectFolder, hash)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return filepath.Join(util.BaseFilePath, util.StageFolder, hash)
 	}
 
-	source := cs.ExtractFileFromObjectStore(diff.PreviousBlobHash)
-	return cs.applyDelta(source, diff)
+	return path
 }
 
-func (cs commitService) applyDelta(source []string, diff myersdiff.Diff) []string {
+func (cs commitService) applyDelta(source []string, diff myersdiff.Diff) ([]string, error) {
 	editScript := strings.Split(diff.Commands, "$")
 	deletedRowCount := 0
 	for _, command := range editScript {
 		if strings.Contains(command, "d") {
 			deletedRow, err := strconv.Atoi(strings.Replace(command, "d", "", 1))
 			if err != nil {
-				log.Fatal("delta calculation error, failed to decompose instructions: " + err.Error())
+				return nil, fmt.Errorf("delta calculation error, failed to decompose instructions: %w", err)
 			}
 			source = append(source[:deletedRow-deletedRowCount], source[deletedRow-deletedRowCount+1:]...)
 			deletedRowCount++
@@ -326,27 +569,35 @@ func (cs commitService) applyDelta(source []string, diff myersdiff.Diff) []strin
 			insert := strings.Split(strings.Replace(command, "i", "", 1), "-")
 			insertionDestIndex, err := strconv.Atoi(insert[0])
 			if err != nil {
-				log.Fatal("delta calculation error, failed to decompose instructions: " + err.Error())
+				return nil, fmt.Errorf("delta calculation error, failed to decompose instructions: %w", err)
 			}
 			insertionSourceIndex, err := strconv.Atoi(insert[1])
 			if err != nil {
-				log.Fatal("delta calculation error, failed to decompose instructions: " + err.Error())
+				return nil, fmt.Errorf("delta calculation error, failed to decompose instructions: %w", err)
 			}
 			source = slices.Insert(source, insertionDestIndex, diff.Data[insertionSourceIndex])
 		}
 	}
 
-	return source
+	return source, nil
 }
 
-func (cs commitService) CalculateSHA1Hash(lines []string) string {
+// CalculateBlobHash hashes exact file content prefixed with a typed header like git does,
+// so that content of different lengths or types never share the same hash input.
+func (cs commitService) CalculateBlobHash(content []byte) string {
 	hasher := sha1.New()
+	hasher.Write([]byte("blob " + strconv.Itoa(len(content)) + "\x00"))
+	hasher.Write(content)
 
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// calculateLegacyBlobHash is the hash of concatenated lines which older versions used
+// to name blobs, it is only used for recognizing those objects.
+func (cs commitService) calculateLegacyBlobHash(lines []string) string {
+	hasher := sha1.New()
 	for _, str := range lines {
-		_, err := hasher.Write([]byte(str))
-		if err != nil {
-			log.Fatal("an error occurred during hash process", err.Error())
-		}
+		hasher.Write([]byte(str))
 	}
 
 	hashSum := hasher.Sum(nil)
@@ -355,33 +606,69 @@ func (cs commitService) CalculateSHA1Hash(lines []string) string {
 	return hashString
 }
 
-func (cs commitService) Log() {
-	commit, err := cs.GetLastCommitOnCurrentBranch()
+// blobMatches reports whether given content is the blob stored with given hash. legacy
+// hashes ignore line boundaries and legacy blobs don't keep line terminators, so lines
+// without terminators are compared when a legacy hash matches.
+func (cs commitService) blobMatches(hash string, content []byte) (bool, error) {
+	if hash == cs.CalculateBlobHash(content) {
+		return true, nil
+	}
 
-	for err == nil {
-		fmt.Printf("\033[32m Commit: %s\n", commit.CalculateHashForCommit())
-		fmt.Printf("\033[34m Author: %s\n", commit.Committer)
-		fmt.Printf("\033[36m Date:   %s\n\n", commit.Date.Format("Mon Jan 2 15:04:05 2006 -0700"))
-		fmt.Printf("\033[31m    %s\n\n", commit.Message)
+	lines := legacyLines(content)
+	if hash != cs.calculateLegacyBlobHash(lines) {
+		return false, nil
+	}
 
-		err = cs.repo.DecompressFromFileAndConvert(filepath.Join(util.BaseFilePath, util.ObjectFolder, commit.PreviousCommit), &commit)
-		if err != nil {
-			break
-		}
+	stored, err := cs.ExtractContentFromObjectStore(hash)
+	if err != nil {
+		return false, err
 	}
+
+	return slices.Equal(legacyLines(stored), lines), nil
 }
 
-func (cs commitService) getPreviousCommit(commitHash string, numberOfCommits int) string {
-	if numberOfCommits == 0 || commitHash == "nil" {
-		return commitHash
+// readWorkingFile returns content of a file in working directory as it would be staged,
+// line endings are normalized according to attributes file.
+func (cs commitService) readWorkingFile(path string) ([]byte, error) {
+	content, err := cs.repo.ReadFile(path)
+	if err != nil {
+		return nil, err
 	}
 
-	var commit Commit
-	err := cs.repo.DecompressFromFileAndConvert(filepath.Join(util.BaseFilePath, util.ObjectFolder, commitHash), &commit)
-	if err != nil {
-		log.Fatal("failed to extract previous commit")
+	return cs.attributes.Clean(path, content), nil
+}
+
+// isBinary uses the same heuristic as git, content having a NUL byte in its first
+// 8000 bytes is treated as binary.
+func isBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), 8000)], 0) != -1
+}
+
+// splitLines splits content into lines keeping their terminators, so that joining them
+// gives back the exact content. last line has no terminator if file doesn't end with one.
+func splitLines(content []byte) []string {
+	lines := strings.SplitAfter(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// legacyLines splits content into lines the way older versions read files, line
+// terminators are dropped.
+func legacyLines(content []byte) []string {
+	lines := strings.Split(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
 	}
 
-	return cs.getPreviousCommit(commit.PreviousCommit, numberOfCommits-1)
+	return lines
+}
 
+func joinLines(lines []string) []byte {
+	return []byte(strings.Join(lines, ""))
 }
@@ -0,0 +1,114 @@
+package checkout
+
+import (
+	"git-light/application/attributes"
+	"git-light/application/hook"
+	"git-light/application/ignore"
+	"git-light/application/myersdiff"
+	"git-light/application/ref"
+	"git-light/application/repository"
+	"git-light/application/revision"
+	"git-light/util"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupCommitService initializes a repository in a temporary directory and makes it the
+// working directory until the test ends.
+func setupCommitService(t *testing.T) commitService {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+
+	repo := repository.NewRepository()
+	refs := ref.NewRefStore(repo)
+	cs := commitService{
+		repo:       repo,
+		myers:      myersdiff.NewMyersDiffCalculator(),
+		hooks:      hook.NewHookRunner(),
+		attributes: attributes.NewAttributeResolver(repo),
+		ignore:     ignore.NewIgnoreMatcher(repo),
+		refs:       refs,
+		revisions:  revision.NewRevisionResolver(repo, refs),
+	}
+	err = cs.Initialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return cs
+}
+
+// writeWorkingFile writes given content to a file in working directory.
+func writeWorkingFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	err := os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.WriteFile(path, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAddToStageRestagedContent(t *testing.T) {
+	cs := setupCommitService(t)
+
+	for _, content := range []string{"one\n", "two\n", "one\n"} {
+		writeWorkingFile(t, "a.txt", content)
+		err := cs.AddToStage([]string{"a.txt"})
+		if err != nil {
+			t.Fatalf("AddToStage() with %q returned error: %v", content, err)
+		}
+	}
+	err := cs.CommitChanges("restage", "tester")
+	if err != nil {
+		t.Fatalf("CommitChanges() returned error: %v", err)
+	}
+
+	for _, content := range []string{"one\n", "two\n"} {
+		got, err := cs.ExtractContentFromObjectStore(cs.CalculateBlobHash([]byte(content)))
+		if err != nil {
+			t.Fatalf("ExtractContentFromObjectStore() for %q returned error: %v", content, err)
+		}
+		if string(got) != content {
+			t.Errorf("ExtractContentFromObjectStore() = %q, want %q", got, content)
+		}
+	}
+
+	err = cs.Checkout(util.DefaultBranchName, true)
+	if err != nil {
+		t.Fatalf("Checkout() returned error: %v", err)
+	}
+}
+
+func TestExtractFileFromObjectStoreCycle(t *testing.T) {
+	cs := setupCommitService(t)
+
+	blobs := map[string]string{"aaaa": "bbbb", "bbbb": "aaaa"}
+	for hash, previous := range blobs {
+		diff := myersdiff.Diff{PreviousBlobHash: previous, Commands: "nil", Exact: true}
+		err := cs.repo.CompressAndSaveToFile(diff, filepath.Join(util.BaseFilePath, util.ObjectFolder, hash))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	_, err := cs.ExtractFileFromObjectStore("aaaa")
+	if err == nil {
+		t.Error("ExtractFileFromObjectStore() on a delta cycle returned no error")
+	}
+}
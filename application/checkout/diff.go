@@ -0,0 +1,263 @@
+package checkout
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"git-light/util"
+	"os"
+	"slices"
+	"strings"
+)
+
+// diffSide is one end of a comparison, files maps paths to blob hashes and load
+// returns content of a path on that side.
+type diffSide struct {
+	files map[string]string
+	load  func(path string) ([]byte, error)
+}
+
+func (cs commitService) Diff(revisions []string, staged bool, contextLines int) error {
+	var from, to diffSide
+	var err error
+
+	switch {
+	case staged:
+		from, err = cs.headSide()
+		if err == nil {
+			to, err = cs.stageSide()
+		}
+	case len(revisions) == 0:
+		from, err = cs.stageSide()
+		if err == nil {
+			to, err = cs.workingSide(from.files)
+		}
+	case len(revisions) == 1 && cs.revisions.IsRange(revisions[0]):
+		var fromHash, toHash string
+		fromHash, toHash, err = cs.revisions.ResolveRange(revisions[0])
+		if err == nil {
+			from, to, err = cs.revisionSides(fromHash, toHash)
+		}
+	case len(revisions) == 1:
+		from, err = cs.revisionSide(revisions[0])
+		if err == nil {
+			to, err = cs.workingSide(from.files)
+		}
+	case len(revisions) == 2:
+		from, to, err = cs.revisionSides(revisions[0], revisions[1])
+	default:
+		err = errors.New("diff accepts at most two commits")
+	}
+	if err != nil {
+		return err
+	}
+
+	return cs.printTreeDiff(from, to, contextLines)
+}
+
+// printTreeDiff prints differences of every path between given sides, deleted and added
+// files detected as renames are shown as a single file.
+func (cs commitService) printTreeDiff(from, to diffSide, contextLines int) error {
+	renames, err := cs.detectRenames(from, to)
+	if err != nil {
+		return err
+	}
+	renamedFrom := make(map[string]rename)
+	renamedTo := make(map[string]bool)
+	for _, r := range renames {
+		renamedFrom[r.To] = r
+		renamedTo[r.From] = true
+	}
+
+	paths := sortedPaths(from.files)
+	for _, path := range sortedPaths(to.files) {
+		if !slices.Contains(paths, path) {
+			paths = append(paths, path)
+		}
+	}
+	slices.Sort(paths)
+
+	for _, path := range paths {
+		fromHash, inFrom := from.files[path]
+		toHash, inTo := to.files[path]
+		if inFrom && inTo && fromHash == toHash || renamedTo[path] {
+			continue
+		}
+
+		if r, ok := renamedFrom[path]; ok {
+			src, err := from.load(r.From)
+			if err != nil {
+				return err
+			}
+			dst, err := to.load(path)
+			if err != nil {
+				return err
+			}
+			cs.printFileDiff(r.From, path, "a/"+r.From, "b/"+path, src, dst, contextLines,
+				fmt.Sprintf("similarity index %d%%", r.Similarity), "rename from "+r.From, "rename to "+path)
+			continue
+		}
+
+		var src, dst []byte
+		srcName, dstName := "a/"+path, "b/"+path
+		if inFrom {
+			src, err = from.load(path)
+			if err != nil {
+				return err
+			}
+		} else {
+			srcName = "/dev/null"
+		}
+		if inTo {
+			dst, err = to.load(path)
+			if err != nil {
+				return err
+			}
+		} else {
+			dstName = "/dev/null"
+		}
+
+		cs.printFileDiff(path, path, srcName, dstName, src, dst, contextLines)
+	}
+
+	return nil
+}
+
+// printFileDiff prints unified diff of a single file, extended header lines like rename
+// information are printed right after the diff header.
+func (cs commitService) printFileDiff(srcPath, dstPath, srcName, dstName string, src, dst []byte, contextLines int, extendedHeader ...string) {
+	color := util.IsTerminal(os.Stdout)
+	printHeader := func() {
+		fmt.Println(colorize(color, "\033[1m", "diff --git-light a/"+srcPath+" b/"+dstPath))
+		for _, line := range extendedHeader {
+			fmt.Println(colorize(color, "\033[1m", line))
+		}
+	}
+
+	if isBinary(src) || isBinary(dst) {
+		printHeader()
+		if !bytes.Equal(src, dst) {
+			fmt.Printf("Binary files %s and %s differ\n", srcName, dstName)
+		}
+		return
+	}
+
+	hunks := cs.myers.GenerateHunks(splitLines(src), splitLines(dst), contextLines)
+	if len(hunks) == 0 && srcName != "/dev/null" && dstName != "/dev/null" {
+		if len(extendedHeader) > 0 {
+			printHeader()
+		}
+		return
+	}
+
+	printHeader()
+	fmt.Println(colorize(color, "\033[1m", "--- "+srcName))
+	fmt.Println(colorize(color, "\033[1m", "+++ "+dstName))
+	for _, hunk := range hunks {
+		fmt.Println(colorize(color, "\033[36m", hunk.Header()))
+		for _, line := range hunk.Lines {
+			text, hasNewline := strings.CutSuffix(line, "\n")
+			switch line[0] {
+			case '+':
+				fmt.Println(colorize(color, "\033[32m", text))
+			case '-':
+				fmt.Println(colorize(color, "\033[31m", text))
+			default:
+				fmt.Println(text)
+			}
+			if !hasNewline {
+				fmt.Println("\\ No newline at end of file")
+			}
+		}
+	}
+}
+
+func (cs commitService) headSide() (diffSide, error) {
+	commit, err := cs.GetLastCommitOnCurrentBranch()
+	if errors.Is(err, errEmptyBranch) {
+		return cs.commitSide(Commit{}), nil
+	}
+	if err != nil {
+		return diffSide{}, err
+	}
+
+	return cs.commitSide(commit), nil
+}
+
+func (cs commitService) stageSide() (diffSide, error) {
+	commit, err := cs.GetStagedCommit()
+	if err != nil {
+		return cs.headSide()
+	}
+
+	return cs.commitSide(commit), nil
+}
+
+// revisionSide builds the side of a commit given as a revision expression.
+func (cs commitService) revisionSide(revision string) (diffSide, error) {
+	commitHash, err := cs.revisions.Resolve(revision)
+	if err != nil {
+		return diffSide{}, err
+	}
+	commit, err := cs.findCommit(commitHash)
+	if err != nil {
+		return diffSide{}, err
+	}
+
+	return cs.commitSide(commit), nil
+}
+
+func (cs commitService) revisionSides(fromRevision, toRevision string) (diffSide, diffSide, error) {
+	from, err := cs.revisionSide(fromRevision)
+	if err != nil {
+		return diffSide{}, diffSide{}, err
+	}
+	to, err := cs.revisionSide(toRevision)
+	if err != nil {
+		return diffSide{}, diffSide{}, err
+	}
+
+	return from, to, nil
+}
+
+func (cs commitService) commitSide(commit Commit) diffSide {
+	files := commit.GetAllFilePaths()
+	return diffSide{
+		files: files,
+		load: func(path string) ([]byte, error) {
+			return cs.ExtractContentFromObjectStore(files[path])
+		},
+	}
+}
+
+// workingSide builds working directory side of a diff, only files tracked by the
+// other side are taken into account like git does for untracked files.
+func (cs commitService) workingSide(tracked map[string]string) (diffSide, error) {
+	files := make(map[string]string)
+	for path, hash := range tracked {
+		content, err := cs.readWorkingFile(path)
+		if err != nil {
+			continue
+		}
+		matches, err := cs.blobMatches(hash, content)
+		if err != nil {
+			return diffSide{}, err
+		}
+		if matches {
+			files[path] = hash
+		} else {
+			files[path] = cs.CalculateBlobHash(content)
+		}
+	}
+
+	return diffSide{
+		files: files,
+		load: func(path string) ([]byte, error) {
+			content, err := cs.readWorkingFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read file from working directory %s: %w", path, err)
+			}
+			return content, nil
+		},
+	}, nil
+}
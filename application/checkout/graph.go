@@ -0,0 +1,112 @@
+package checkout
+
+import (
+	"strings"
+)
+
+// logGraph draws history as ASCII columns next to log output. each column holds the hash
+// of the commit expected to be printed next on that line of history.
+type logGraph struct {
+	columns []string
+}
+
+// graphEdge is a line of history moving from a column before a commit is printed to its
+// column after it.
+type graphEdge struct {
+	from int
+	to   int
+}
+
+// next places given commit in the graph and returns the line marking it, the lines which
+// connect it to its parents and the prefix of lines printed after those.
+func (g *logGraph) next(commitHash string, parents []string) (string, []string, string) {
+	column := indexOf(g.columns, commitHash)
+	if column == -1 {
+		g.columns = append(g.columns, commitHash)
+		column = len(g.columns) - 1
+	}
+
+	marks := make([]string, len(g.columns))
+	for i := range marks {
+		marks[i] = "|"
+	}
+	marks[column] = "*"
+	commitLine := strings.Join(marks, " ")
+
+	fresh := make([]string, 0)
+	for _, parent := range parents {
+		if indexOf(g.columns, parent) == -1 && indexOf(fresh, parent) == -1 {
+			fresh = append(fresh, parent)
+		}
+	}
+	columns := make([]string, 0, len(g.columns)+len(fresh))
+	columns = append(columns, g.columns[:column]...)
+	columns = append(columns, fresh...)
+	columns = append(columns, g.columns[column+1:]...)
+
+	edges := make([]graphEdge, 0)
+	for i, hash := range g.columns {
+		if i != column {
+			edges = append(edges, graphEdge{from: i, to: indexOf(columns, hash)})
+		}
+	}
+	for _, parent := range parents {
+		edges = append(edges, graphEdge{from: column, to: indexOf(columns, parent)})
+	}
+	g.columns = columns
+
+	marks = make([]string, len(columns))
+	for i := range marks {
+		marks[i] = "|"
+	}
+
+	return commitLine, drawGraphEdges(edges), strings.Join(marks, " ")
+}
+
+// drawGraphEdges returns lines moving every edge one column per line until all of them
+// reach their target columns.
+func drawGraphEdges(edges []graphEdge) []string {
+	lines := make([]string, 0)
+	for {
+		moving := false
+		for _, edge := range edges {
+			if edge.from != edge.to {
+				moving = true
+			}
+		}
+		if !moving {
+			return lines
+		}
+
+		width := 0
+		for _, edge := range edges {
+			if 2*edge.from+2 > width {
+				width = 2*edge.from + 2
+			}
+		}
+		line := []byte(strings.Repeat(" ", width))
+		for i, edge := range edges {
+			switch {
+			case edge.from < edge.to:
+				line[2*edge.from+1] = '\\'
+				edges[i].from++
+			case edge.from > edge.to:
+				line[2*edge.from-1] = '/'
+				edges[i].from--
+			default:
+				line[2*edge.from] = '|'
+			}
+		}
+		lines = append(lines, strings.TrimRight(string(line), " "))
+	}
+}
+
+func indexOf(values []string, value string) int {
+	for i, v := range values {
+		if v == value {
+			return i
+		}
+	}
+
+	return -1
+}
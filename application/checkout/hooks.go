@@ -0,0 +1,41 @@
+package checkout
+
+import (
+	"fmt"
+	"git-light/application/hook"
+	"git-light/application/ref"
+	"git-light/util"
+	"path/filepath"
+	"strings"
+)
+
+// hookEnv returns environment variables which are passed to every hook.
+func (cs commitService) hookEnv(head ref.Head, commitHash string) []string {
+	return []string{
+		hook.CommitEnv + "=" + commitHash,
+		hook.BranchEnv + "=" + head.Branch,
+	}
+}
+
+// runCommitMsgHook writes message to a temporary file and passes its path to commit-msg
+// hook, hook may rewrite the file so message is read back after it exits.
+func (cs commitService) runCommitMsgHook(commitMessage string, env []string) (string, error) {
+	messageFile := filepath.Join(util.BaseFilePath, util.CommitMessageFile)
+	err := cs.repo.WriteToFile(messageFile, strings.Split(commitMessage, "\n"))
+	if err != nil {
+		return "", fmt.Errorf("failed to write commit message for commit-msg hook: %w", err)
+	}
+	defer cs.repo.DeleteFiles(messageFile)
+
+	err = cs.hooks.Run(hook.CommitMsg, []string{messageFile}, env)
+	if err != nil {
+		return "", fmt.Errorf("commit-msg hook failed, aborting commit process: %w", err)
+	}
+
+	lines, err := cs.repo.GetFileLines(messageFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read commit message back from commit-msg hook: %w", err)
+	}
+
+	return strings.TrimRight(strings.Join(lines, "\n"), "\n"), nil
+}
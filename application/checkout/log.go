@@ -0,0 +1,627 @@
+package checkout
+
+import (
+	"errors"
+	"fmt"
+	"git-light/util"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	shortHashLength = 7
+	logDateLayout   = "Mon Jan 2 15:04:05 2006 -0700"
+)
+
+// LogOptions selects and formats commits printed by Log. revisions may be single
+// revisions or A..B ranges, HEAD is used when none is given. MaxCount below zero means
+// no limit, zero values of other filters disable them.
+type LogOptions struct {
+	Revisions  []string
+	Paths      []string
+	All        bool
+	MaxCount   int
+	Author     string
+	Grep       string
+	Since      string
+	Until      string
+	Oneline    bool
+	Format     string
+	Graph      bool
+	NameStatus bool
+}
+
+// logEntry is a commit selected to be printed, parents are limited to printed commits
+// when graph is drawn.
+type logEntry struct {
+	hash    string
+	commit  Commit
+	parents []string
+}
+
+// Log prints commits reachable from given revisions newest first, a commit is never printed
+// before its children. filters are applied before the number of commits is limited.
+func (cs commitService) Log(options LogOptions) error {
+	starts, excluded, err := cs.logStartPoints(options)
+	if err != nil {
+		return err
+	}
+	commits, err := cs.collectLogCommits(starts, excluded)
+	if err != nil {
+		return err
+	}
+
+	filter, err := cs.newLogFilter(options)
+	if err != nil {
+		return err
+	}
+	entries := make([]logEntry, 0)
+	err = walkLogCommits(starts, commits, func(hash string) (bool, error) {
+		if options.MaxCount >= 0 && len(entries) >= options.MaxCount {
+			return false, nil
+		}
+		matches, err := filter(hash, commits[hash])
+		if err != nil {
+			return false, err
+		}
+		if matches {
+			entries = append(entries, logEntry{hash: hash, commit: commits[hash]})
+		}
+		return options.MaxCount < 0 || len(entries) < options.MaxCount, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if options.Graph {
+		rewriteLogParents(entries, commits)
+	}
+
+	color := util.IsTerminal(os.Stdout)
+	graph := logGraph{}
+	for _, entry := range entries {
+		lines, err := cs.formatLogEntry(entry, options, color)
+		if err != nil {
+			return err
+		}
+		if !options.Graph {
+			for _, line := range lines {
+				fmt.Println(line)
+			}
+			continue
+		}
+
+		commitLine, transitions, padding := graph.next(entry.hash, entry.parents)
+		prefixes := append([]string{commitLine}, transitions...)
+		width := len(padding)
+		for _, prefix := range prefixes {
+			if len(prefix) > width {
+				width = len(prefix)
+			}
+		}
+		for i := 0; i < len(lines) || i < len(prefixes); i++ {
+			prefix, line := padding, ""
+			if i < len(prefixes) {
+				prefix = prefixes[i]
+			}
+			if i < len(lines) {
+				line = lines[i]
+			}
+			fmt.Println(strings.TrimRight(fmt.Sprintf("%-*s %s", width, prefix, line), " "))
+		}
+	}
+
+	return nil
+}
+
+// logStartPoints returns commits to start walking from and commits which must be left
+// out together with their ancestors.
+func (cs commitService) logStartPoints(options LogOptions) ([]string, map[string]bool, error) {
+	starts := make([]string, 0)
+	excluded := make(map[string]bool)
+
+	head, err := cs.readHead()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, rev := range options.Revisions {
+		if cs.revisions.IsRange(rev) {
+			from, to, err := cs.revisions.ResolveRange(rev)
+			if err != nil {
+				return nil, nil, err
+			}
+			ancestors, err := cs.collectAncestors(from)
+			if err != nil {
+				return nil, nil, err
+			}
+			for hash := range ancestors {
+				excluded[hash] = true
+			}
+			starts = append(starts, to)
+		} else {
+			commitHash, err := cs.revisions.Resolve(rev)
+			if err != nil {
+				return nil, nil, err
+			}
+			starts = append(starts, commitHash)
+		}
+	}
+
+	if options.All {
+		if head.Hash != "nil" {
+			starts = append(starts, head.Hash)
+		}
+		tips, err := cs.branchTips()
+		if err != nil {
+			return nil, nil, err
+		}
+		starts = append(starts, tips...)
+		tagNames, err := cs.refs.ListTags()
+		if err != nil {
+			return nil, nil, fmt.Errorf("couldn't get list of tags: %w", err)
+		}
+		for _, tagName := range tagNames {
+			commitHash, err := cs.revisions.Resolve(tagName)
+			if err != nil {
+				return nil, nil, err
+			}
+			starts = append(starts, commitHash)
+		}
+	} else if len(options.Revisions) == 0 && head.Hash != "nil" {
+		starts = append(starts, head.Hash)
+	}
+
+	return starts, excluded, nil
+}
+
+func (cs commitService) collectLogCommits(starts []string, excluded map[string]bool) (map[string]Commit, error) {
+	commits := make(map[string]Commit)
+	queue := append([]string{}, starts...)
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if _, ok := commits[hash]; ok || excluded[hash] {
+			continue
+		}
+
+		commit, err := cs.findCommit(hash)
+		if err != nil {
+			return nil, err
+		}
+		commits[hash] = commit
+		queue = append(queue, commit.GetParents()...)
+	}
+
+	return commits, nil
+}
+
+// orderLogCommits sorts commits newest first while keeping every commit after all of its
+// children, so that graph edges always point downwards.
+func orderLogCommits(starts []string, commits map[string]Commit) ([]string, error) {
+	order := make([]string, 0, len(commits))
+	err := walkLogCommits(starts, commits, func(hash string) (bool, error) {
+		order = append(order, hash)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// walkLogCommits visits commits in the order of orderLogCommits until visit returns false.
+// legacy hashes don't cover parents, so a legacy history may lead back to one of its own
+// commits. such commits never lose all of their children and are reported as an error.
+func walkLogCommits(starts []string, commits map[string]Commit, visit func(hash string) (bool, error)) error {
+	children := make(map[string]int)
+	for _, commit := range commits {
+		for _, parent := range commit.GetParents() {
+			if _, ok := commits[parent]; ok {
+				children[parent]++
+			}
+		}
+	}
+
+	ready := make([]string, 0)
+	for _, hash := range starts {
+		if _, ok := commits[hash]; ok && children[hash] == 0 && indexOf(ready, hash) == -1 {
+			ready = append(ready, hash)
+		}
+	}
+
+	visited := 0
+	for len(ready) > 0 {
+		newest := 0
+		for i := range ready {
+			if commits[ready[i]].Date.After(commits[ready[newest]].Date) {
+				newest = i
+			}
+		}
+		hash := ready[newest]
+		ready = append(ready[:newest], ready[newest+1:]...)
+		visited++
+		more, err := visit(hash)
+		if err != nil || !more {
+			return err
+		}
+
+		for _, parent := range commits[hash].GetParents() {
+			if _, ok := commits[parent]; !ok {
+				continue
+			}
+			children[parent]--
+			if children[parent] == 0 && indexOf(ready, parent) == -1 {
+				ready = append(ready, parent)
+			}
+		}
+	}
+
+	if visited < len(commits) {
+		return errors.New("commit history leads back to one of its own commits, run 'git-light migrate' to repair it")
+	}
+
+	return nil
+}
+
+// rewriteLogParents replaces parents of printed commits which are filtered out with their
+// nearest printed ancestors, so that graph stays connected.
+func rewriteLogParents(entries []logEntry, commits map[string]Commit) {
+	printed := make(map[string]bool)
+	for _, entry := range entries {
+		printed[entry.hash] = true
+	}
+
+	var visibleParents func(hash string, visited map[string]bool) []string
+	visibleParents = func(hash string, visited map[string]bool) []string {
+		parents := make([]string, 0)
+		for _, parent := range commits[hash].GetParents() {
+			if visited[parent] {
+				continue
+			}
+			visited[parent] = true
+			if printed[parent] {
+				parents = append(parents, parent)
+			} else if _, ok := commits[parent]; ok {
+				parents = append(parents, visibleParents(parent, visited)...)
+			}
+		}
+		return parents
+	}
+
+	for i := range entries {
+		entries[i].parents = visibleParents(entries[i].hash, make(map[string]bool))
+	}
+}
+
+// newLogFilter returns a function which reports whether a commit passes author, message,
+// date and path filters of given options.
+func (cs commitService) newLogFilter(options LogOptions) (func(hash string, commit Commit) (bool, error), error) {
+	var author, grep *regexp.Regexp
+	var err error
+	if options.Author != "" {
+		author, err = regexp.Compile("(?i)" + options.Author)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --author pattern: %w", err)
+		}
+	}
+	if options.Grep != "" {
+		grep, err = regexp.Compile(options.Grep)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --grep pattern: %w", err)
+		}
+	}
+	since, err := parseLogDate(options.Since, "--since")
+	if err != nil {
+		return nil, err
+	}
+	until, err := parseLogDate(options.Until, "--until")
+	if err != nil {
+		return nil, err
+	}
+
+	return func(hash string, commit Commit) (bool, error) {
+		if author != nil && !author.MatchString(commit.Committer) {
+			return false, nil
+		}
+		if grep != nil && !grep.MatchString(commit.Message) {
+			return false, nil
+		}
+		if !since.IsZero() && commit.Date.Before(since) {
+			return false, nil
+		}
+		if !until.IsZero() && commit.Date.After(until) {
+			return false, nil
+		}
+		if len(options.Paths) == 0 {
+			return true, nil
+		}
+
+		return cs.commitTouchesPaths(commit, options.Paths)
+	}, nil
+}
+
+// commitTouchesPaths reports whether any file under given paths differs between commit
+// and its first parent.
+func (cs commitService) commitTouchesPaths(commit Commit, paths []string) (bool, error) {
+	parent, err := cs.firstParent(commit)
+	if err != nil {
+		return false, err
+	}
+
+	parentFiles := parent.GetAllFilePaths()
+	files := commit.GetAllFilePaths()
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		for _, tree := range []Commit{commit, parent} {
+			for _, filePath := range tree.GetFilesUnder(path) {
+				hash, inParent := parentFiles[filePath]
+				newHash, inCommit := files[filePath]
+				if inParent != inCommit || hash != newHash {
+					return true, nil
+				}
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// firstParent returns first parent of given commit, empty commit is returned for root
+// commits.
+func (cs commitService) firstParent(commit Commit) (Commit, error) {
+	parents := commit.GetParents()
+	if len(parents) == 0 {
+		return Commit{}, nil
+	}
+
+	return cs.findCommit(parents[0])
+}
+
+// parseLogDate accepts dates like 2006-01-02, 2006-01-02 15:04:05, RFC 3339 timestamps and
+// relative dates like "2 weeks ago". empty value gives zero time.
+func parseLogDate(value string, flag string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if date, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return date, nil
+		}
+	}
+
+	fields := strings.Fields(strings.ReplaceAll(value, ".", " "))
+	if len(fields) == 3 && fields[2] == "ago" {
+		n, err := strconv.Atoi(fields[0])
+		if err == nil {
+			now := time.Now()
+			switch strings.TrimSuffix(fields[1], "s") {
+			case "second":
+				return now.Add(-time.Duration(n) * time.Second), nil
+			case "minute":
+				return now.Add(-time.Duration(n) * time.Minute), nil
+			case "hour":
+				return now.Add(-time.Duration(n) * time.Hour), nil
+			case "day":
+				return now.AddDate(0, 0, -n), nil
+			case "week":
+				return now.AddDate(0, 0, -7*n), nil
+			case "month":
+				return now.AddDate(0, -n, 0), nil
+			case "year":
+				return now.AddDate(-n, 0, 0), nil
+			}
+		}
+	}
+
+	return time.Time{}, errors.New("invalid date for " + flag + ": " + value)
+}
+
+// formatLogEntry returns lines printed for a commit, first line is the one graph marks
+// with the commit node.
+func (cs commitService) formatLogEntry(entry logEntry, options LogOptions, color bool) ([]string, error) {
+	var lines []string
+	switch {
+	case options.Format != "":
+		lines = strings.Split(formatLogTemplate(options.Format, entry, color), "\n")
+	case options.Oneline:
+		subject, _, _ := strings.Cut(entry.commit.Message, "\n")
+		lines = []string{colorize(color, "\033[33m", shortHash(entry.hash)) + " " + subject}
+	default:
+		lines = []string{colorize(color, "\033[32m", " Commit: "+entry.hash)}
+		if parents := entry.commit.GetParents(); len(parents) > 1 {
+			lines = append(lines, colorize(color, "\033[33m", " Merge:  "+strings.Join(parents, " ")))
+		}
+		lines = append(lines, colorize(color, "\033[34m", " Author: "+entry.commit.Committer))
+		lines = append(lines, colorize(color, "\033[36m", " Date:   "+entry.commit.Date.Format(logDateLayout)))
+		lines = append(lines, "")
+		for _, line := range strings.Split(entry.commit.Message, "\n") {
+			lines = append(lines, colorize(color, "\033[31m", "    "+line))
+		}
+		lines = append(lines, "")
+	}
+
+	if options.NameStatus {
+		nameStatus, err := cs.nameStatusLines(entry.commit)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, nameStatus...)
+		lines = append(lines, "")
+	}
+
+	return lines, nil
+}
+
+// formatLogTemplate expands placeholders of a --format template:
+//
+//	%H %h   commit hash, abbreviated commit hash
+//	%P %p   parent hashes, abbreviated parent hashes
+//	%an %ae committer (author and committer are the same person in git-light)
+//	%cn %ce committer
+//	%ad %ai date, ISO 8601 like date
+//	%at     date as unix timestamp
+//	%ar     relative date
+//	%s %b   subject, body
+//	%B      raw message
+//	%Cred %Cgreen %Cblue %Cyellow %Creset colours
+//	%n %%   newline, percent sign
+func formatLogTemplate(template string, entry logEntry, color bool) string {
+	commit := entry.commit
+	subject, body, _ := strings.Cut(commit.Message, "\n")
+	parents := commit.GetParents()
+	shortParents := make([]string, len(parents))
+	for i, parent := range parents {
+		shortParents[i] = shortHash(parent)
+	}
+
+	placeholders := []struct {
+		name  string
+		value string
+	}{
+		{"%Cyellow", colorCode(color, "\033[33m")},
+		{"%Creset", colorCode(color, "\033[0m")},
+		{"%Cgreen", colorCode(color, "\033[32m")},
+		{"%Cblue", colorCode(color, "\033[34m")},
+		{"%Cred", colorCode(color, "\033[31m")},
+		{"%H", entry.hash},
+		{"%h", shortHash(entry.hash)},
+		{"%P", strings.Join(parents, " ")},
+		{"%p", strings.Join(shortParents, " ")},
+		{"%an", commit.Committer},
+		{"%ae", commit.Committer},
+		{"%cn", commit.Committer},
+		{"%ce", commit.Committer},
+		{"%ad", commit.Date.Format(logDateLayout)},
+		{"%ai", commit.Date.Format("2006-01-02 15:04:05 -0700")},
+		{"%at", strconv.FormatInt(commit.Date.Unix(), 10)},
+		{"%ar", relativeDate(commit.Date)},
+		{"%s", subject},
+		{"%b", strings.TrimLeft(body, "\n")},
+		{"%B", commit.Message},
+		{"%n", "\n"},
+		{"%%", "%"},
+	}
+
+	var out strings.Builder
+	for i := 0; i < len(template); {
+		var matched = false
+		for _, placeholder := range placeholders {
+			if strings.HasPrefix(template[i:], placeholder.name) {
+				out.WriteString(placeholder.value)
+				i += len(placeholder.name)
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			out.WriteByte(template[i])
+			i++
+		}
+	}
+
+	return out.String()
+}
+
+func relativeDate(date time.Time) string {
+	elapsed := time.Since(date)
+	units := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"week", 7 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, unit := range units {
+		if n := int(elapsed / unit.duration); n > 0 {
+			if n == 1 {
+				return "1 " + unit.name + " ago"
+			}
+			return strconv.Itoa(n) + " " + unit.name + "s ago"
+		}
+	}
+
+	return "just now"
+}
+
+// nameStatusLines lists files added, modified, deleted or renamed by given commit compared
+// to its first parent, binary files are marked.
+func (cs commitService) nameStatusLines(commit Commit) ([]string, error) {
+	parent, err := cs.firstParent(commit)
+	if err != nil {
+		return nil, err
+	}
+	parentFiles := parent.GetAllFilePaths()
+	files := commit.GetAllFilePaths()
+
+	renames, err := cs.detectRenames(cs.commitSide(parent), cs.commitSide(commit))
+	if err != nil {
+		return nil, err
+	}
+	renamedFrom := make(map[string]rename)
+	renamedTo := make(map[string]bool)
+	for _, r := range renames {
+		renamedFrom[r.To] = r
+		renamedTo[r.From] = true
+	}
+
+	lines := make([]string, 0)
+	for _, path := range sortedPaths(files) {
+		if r, ok := renamedFrom[path]; ok {
+			lines = append(lines, nameStatusLine(fmt.Sprintf("R%03d", r.Similarity), r.From+" -> "+path, commit.GetFile(path).Binary))
+		} else if hash, ok := parentFiles[path]; !ok {
+			lines = append(lines, nameStatusLine("A", path, commit.GetFile(path).Binary))
+		} else if hash != files[path] {
+			lines = append(lines, nameStatusLine("M", path, commit.GetFile(path).Binary))
+		}
+	}
+	for _, path := range sortedPaths(parentFiles) {
+		if _, ok := files[path]; !ok && !renamedTo[path] {
+			lines = append(lines, nameStatusLine("D", path, parent.GetFile(path).Binary))
+		}
+	}
+
+	return lines, nil
+}
+
+func nameStatusLine(code string, path string, binary bool) string {
+	if binary {
+		return " " + code + "\t" + path + " (binary)"
+	}
+
+	return " " + code + "\t" + path
+}
+
+func shortHash(hash string) string {
+	if len(hash) > shortHashLength {
+		return hash[:shortHashLength]
+	}
+
+	return hash
+}
+
+func colorize(color bool, code string, text string) string {
+	if !color {
+		return text
+	}
+
+	return code + text + "\033[0m"
+}
+
+func colorCode(color bool, code string) string {
+	if !color {
+		return ""
+	}
+
+	return code
+}
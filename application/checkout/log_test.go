@@ -0,0 +1,77 @@
+package checkout
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestWalkLogCommits(t *testing.T) {
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	commit := func(minutes int, parents ...string) Commit {
+		return Commit{Date: date.Add(time.Duration(minutes) * time.Minute), Parents: parents}
+	}
+
+	tests := []struct {
+		name    string
+		commits map[string]Commit
+		limit   int
+		want    []string
+		wantErr bool
+	}{
+		{
+			name:    "linear history",
+			commits: map[string]Commit{"a": commit(3, "b"), "b": commit(2, "c"), "c": commit(1)},
+			limit:   -1,
+			want:    []string{"a", "b", "c"},
+		},
+		{
+			name:    "children before parents with skewed dates",
+			commits: map[string]Commit{"a": commit(1, "b", "c"), "b": commit(3, "d"), "c": commit(2, "d"), "d": commit(4)},
+			limit:   -1,
+			want:    []string{"a", "b", "c", "d"},
+		},
+		{
+			name:    "stops at limit",
+			commits: map[string]Commit{"a": commit(3, "b"), "b": commit(2, "c"), "c": commit(1)},
+			limit:   2,
+			want:    []string{"a", "b"},
+		},
+		{
+			name:    "commit is its own ancestor",
+			commits: map[string]Commit{"a": commit(2, "b"), "b": commit(1, "a")},
+			limit:   -1,
+			want:    []string{},
+			wantErr: true,
+		},
+		{
+			name:    "cycle below start",
+			commits: map[string]Commit{"a": commit(3, "b"), "b": commit(2, "c"), "c": commit(1, "b")},
+			limit:   -1,
+			want:    []string{"a"},
+			wantErr: true,
+		},
+		{
+			name:    "cycle below limit is not walked",
+			commits: map[string]Commit{"a": commit(3, "b"), "b": commit(2, "c"), "c": commit(1, "b")},
+			limit:   1,
+			want:    []string{"a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make([]string, 0)
+			err := walkLogCommits([]string{"a"}, tt.commits, func(hash string) (bool, error) {
+				got = append(got, hash)
+				return tt.limit < 0 || len(got) < tt.limit, nil
+			})
+			if tt.wantErr != (err != nil) {
+				t.Errorf("walkLogCommits() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("walkLogCommits() visited %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,397 @@
+package checkout
+
+import (
+	"errors"
+	"fmt"
+	"git-light/application/myersdiff"
+	"git-light/util"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+func (cs commitService) Merge(branchName string, committer string) error {
+	err := cs.requireCleanTree("merging")
+	if err != nil {
+		return err
+	}
+
+	head, err := cs.readHead()
+	if err != nil {
+		return err
+	}
+	oursHash := head.Hash
+	if oursHash == "nil" {
+		return errors.New("current branch does not have any commits to merge into")
+	}
+	theirsHash, err := cs.revisions.Resolve(branchName)
+	if err != nil {
+		return err
+	}
+	ours, err := cs.findCommit(oursHash)
+	if err != nil {
+		return err
+	}
+	theirs, err := cs.findCommit(theirsHash)
+	if err != nil {
+		return err
+	}
+
+	baseHash, err := cs.findMergeBase(oursHash, theirsHash)
+	if err != nil {
+		return err
+	}
+	if baseHash == theirsHash {
+		fmt.Println("Already up to date.")
+		return nil
+	}
+
+	var base Commit
+	if baseHash != "" {
+		base, err = cs.findCommit(baseHash)
+		if err != nil {
+			return err
+		}
+	}
+	err = cs.requireNoUntrackedOverwrite(base, ours, theirs, "merge")
+	if err != nil {
+		return err
+	}
+
+	if baseHash == oursHash {
+		fmt.Println("Fast-forward to " + theirsHash)
+		err = cs.checkoutTree(ours.GetAllFilePaths(), theirs.GetAllFilePaths(), false)
+		if err != nil {
+			return err
+		}
+		return cs.updateHead(theirsHash, "merge "+branchName+": Fast-forward")
+	}
+
+	stageCommit, conflicts, err := cs.mergeTrees(base, ours, theirs, branchName)
+	if err != nil {
+		return err
+	}
+	stageCommit.PreviousCommit = oursHash
+
+	err = cs.repo.CompressAndSaveToFile(stageCommit, filepath.Join(util.BaseFilePath, util.StageFolder, "commit"))
+	if err != nil {
+		return fmt.Errorf("failed to save merge result to stage: %w", err)
+	}
+
+	err = cs.repo.WriteToFile(filepath.Join(util.BaseFilePath, util.MergeHead), []string{theirsHash})
+	if err != nil {
+		return fmt.Errorf("failed to save merge state: %w", err)
+	}
+
+	if len(conflicts) > 0 {
+		return util.ConflictError{Message: "automatic merge failed; fix conflicts, add them and then commit the result", Paths: conflicts}
+	}
+
+	return cs.CommitChanges("Merge branch '"+branchName+"'", committer)
+}
+
+func (cs commitService) AbortMerge() error {
+	if !cs.isMerging() {
+		return errors.New("there is no merge in progress")
+	}
+
+	mergeHead, err := cs.repo.GetFileLines(filepath.Join(util.BaseFilePath, util.MergeHead))
+	if err != nil {
+		return fmt.Errorf("failed to read merge state: %w", err)
+	}
+
+	theirs, err := cs.findCommit(mergeHead[0])
+	if err != nil {
+		return err
+	}
+	err = cs.restoreHead(theirs.GetAllFilePaths())
+	if err != nil {
+		return err
+	}
+	err = cs.repo.DeleteFiles(filepath.Join(util.BaseFilePath, util.MergeHead))
+	if err != nil {
+		return fmt.Errorf("failed to clear merge state: %w", err)
+	}
+
+	return nil
+}
+
+// mergeTrees merges each file of ours and theirs according to their merge base, results are
+// written to working directory and returned as the commit to be staged. conflicting paths
+// are staged as ours and recorded as unmerged until they are added again.
+func (cs commitService) mergeTrees(base, ours, theirs Commit, theirsLabel string) (Commit, []string, error) {
+	baseFiles := base.GetAllFilePaths()
+	oursFiles := ours.GetAllFilePaths()
+	theirsFiles := theirs.GetAllFilePaths()
+
+	paths := sortedPaths(baseFiles)
+	for _, files := range []map[string]string{oursFiles, theirsFiles} {
+		for path := range files {
+			if !slices.Contains(paths, path) {
+				paths = append(paths, path)
+			}
+		}
+	}
+	slices.Sort(paths)
+
+	stageCommit := Commit{Files: make([]File, 0)}
+	conflicts := make([]string, 0)
+
+	for _, path := range paths {
+		baseHash, inBase := baseFiles[path]
+		oursHash, inOurs := oursFiles[path]
+		theirsHash, inTheirs := theirsFiles[path]
+
+		var err error
+		switch {
+		case inOurs == inTheirs && oursHash == theirsHash:
+			if inOurs {
+				stageCommit.Files = append(stageCommit.Files, ours.GetFile(path))
+			}
+		case inBase == inOurs && baseHash == oursHash:
+			if inTheirs {
+				stageCommit.Files = append(stageCommit.Files, theirs.GetFile(path))
+				err = cs.writeBlob(path, theirsHash)
+			} else {
+				err = cs.removeWorkingFile(path)
+			}
+		case inBase == inTheirs && baseHash == theirsHash:
+			if inOurs {
+				stageCommit.Files = append(stageCommit.Files, ours.GetFile(path))
+			}
+		case !inOurs || !inTheirs || ours.GetFile(path).Binary || theirs.GetFile(path).Binary:
+			if inOurs {
+				stageCommit.Files = append(stageCommit.Files, ours.GetFile(path))
+			} else {
+				err = cs.writeBlob(path, theirsHash)
+			}
+			conflicts = append(conflicts, path)
+		default:
+			var file File
+			var hasConflict bool
+			file, hasConflict, err = cs.mergeFile(path, baseHash, oursHash, theirsHash, inBase, theirsLabel)
+			if hasConflict {
+				stageCommit.Files = append(stageCommit.Files, ours.GetFile(path))
+				conflicts = append(conflicts, path)
+			} else {
+				stageCommit.Files = append(stageCommit.Files, file)
+			}
+		}
+		if err != nil {
+			return Commit{}, nil, err
+		}
+	}
+
+	err := cs.saveUnmergedPaths(conflicts)
+	if err != nil {
+		return Commit{}, nil, err
+	}
+
+	return stageCommit, conflicts, nil
+}
+
+// mergeFile merges text changes of a single file and writes the result to working
+// directory, conflicting hunks are written with conflict markers and nothing is staged.
+func (cs commitService) mergeFile(path, baseHash, oursHash, theirsHash string, inBase bool, theirsLabel string) (File, bool, error) {
+	var baseLines []string
+	var err error
+	if inBase {
+		baseLines, err = cs.ExtractFileFromObjectStore(baseHash)
+		if err != nil {
+			return File{}, false, err
+		}
+	}
+	oursLines, err := cs.ExtractFileFromObjectStore(oursHash)
+	if err != nil {
+		return File{}, false, err
+	}
+	theirsLines, err := cs.ExtractFileFromObjectStore(theirsHash)
+	if err != nil {
+		return File{}, false, err
+	}
+
+	merged, hasConflict := cs.myers.Merge(baseLines, oursLines, theirsLines, util.Head, theirsLabel)
+	err = cs.writeWorkingFile(path, joinLines(merged))
+	if err != nil || hasConflict {
+		return File{}, hasConflict, err
+	}
+
+	file, err := cs.stageFileContent(path, joinLines(merged), oursHash)
+	return file, false, err
+}
+
+// findMergeBase returns the best common ancestor of given commits, empty string is
+// returned when histories are unrelated.
+func (cs commitService) findMergeBase(ours, theirs string) (string, error) {
+	oursAncestors, err := cs.collectAncestors(ours)
+	if err != nil {
+		return "", err
+	}
+
+	candidates := make([]string, 0)
+	visited := make(map[string]bool)
+	queue := []string{theirs}
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if visited[hash] {
+			continue
+		}
+		visited[hash] = true
+
+		if oursAncestors[hash] {
+			candidates = append(candidates, hash)
+			continue
+		}
+		commit, err := cs.findCommit(hash)
+		if err != nil {
+			return "", err
+		}
+		queue = append(queue, commit.GetParents()...)
+	}
+
+	for _, candidate := range candidates {
+		var redundant = false
+		for _, other := range candidates {
+			if other == candidate {
+				continue
+			}
+			ancestors, err := cs.collectAncestors(other)
+			if err != nil {
+				return "", err
+			}
+			if ancestors[candidate] {
+				redundant = true
+				break
+			}
+		}
+		if !redundant {
+			return candidate, nil
+		}
+	}
+
+	return "", nil
+}
+
+func (cs commitService) collectAncestors(commitHash string) (map[string]bool, error) {
+	ancestors := make(map[string]bool)
+	queue := []string{commitHash}
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if ancestors[hash] {
+			continue
+		}
+		ancestors[hash] = true
+		commit, err := cs.findCommit(hash)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, commit.GetParents()...)
+	}
+
+	return ancestors, nil
+}
+
+// saveBlobToStage saves given delta to staging area unless an object with the same
+// hash exists already in object store or staging area, overwriting it could make two
+// deltas point to each other.
+func (cs commitService) saveBlobToStage(hash string, diff myersdiff.Diff) error {
+	if _, err := os.Stat(filepath.Join(util.BaseFilePath, util.ObjectFolder, hash)); err == nil {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(util.BaseFilePath, util.StageFolder, hash)); err == nil {
+		return nil
+	}
+
+	err := cs.repo.CompressAndSaveToFile(diff, filepath.Join(util.BaseFilePath, util.StageFolder, hash))
+	if err != nil {
+		return fmt.Errorf("failed to save file to stage with hash %s: %w", hash, err)
+	}
+
+	return nil
+}
+
+func (cs commitService) clearStage() error {
+	stagedFiles, err := cs.repo.ListAllFiles(filepath.Join(util.BaseFilePath, util.StageFolder))
+	if err != nil {
+		return fmt.Errorf("failed to list staging area: %w", err)
+	}
+
+	for _, path := range stagedFiles {
+		err = cs.repo.DeleteFiles(path)
+		if err != nil {
+			return fmt.Errorf("failed to clear staging area: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (cs commitService) isMerging() bool {
+	_, err := os.Stat(filepath.Join(util.BaseFilePath, util.MergeHead))
+	return err == nil
+}
+
+// saveUnmergedPaths records paths which were left with conflicts, committing is refused
+// until each of them is added again.
+func (cs commitService) saveUnmergedPaths(paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	err := cs.repo.WriteToFile(filepath.Join(util.BaseFilePath, util.UnmergedPaths), paths)
+	if err != nil {
+		return fmt.Errorf("failed to save unmerged paths: %w", err)
+	}
+
+	return nil
+}
+
+func (cs commitService) readUnmergedPaths() ([]string, error) {
+	paths, err := cs.repo.GetFileLines(filepath.Join(util.BaseFilePath, util.UnmergedPaths))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read unmerged paths: %w", err)
+	}
+
+	return paths, nil
+}
+
+// resolveUnmergedPaths drops given paths from unmerged paths, they are resolved once added.
+func (cs commitService) resolveUnmergedPaths(resolved []string) error {
+	paths, err := cs.readUnmergedPaths()
+	if err != nil || len(paths) == 0 {
+		return err
+	}
+
+	remaining := slices.DeleteFunc(paths, func(path string) bool {
+		return slices.Contains(resolved, path)
+	})
+	if len(remaining) == 0 {
+		return cs.clearUnmergedPaths()
+	}
+
+	return cs.saveUnmergedPaths(remaining)
+}
+
+func (cs commitService) clearUnmergedPaths() error {
+	err := cs.repo.DeleteFiles(filepath.Join(util.BaseFilePath, util.UnmergedPaths))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear unmerged paths: %w", err)
+	}
+
+	return nil
+}
+
+// requireMerged returns a util.ConflictError listing unmerged paths when there are any.
+func (cs commitService) requireMerged(operation string) error {
+	paths, err := cs.readUnmergedPaths()
+	if err != nil {
+		return err
+	}
+	if len(paths) > 0 {
+		return util.ConflictError{Message: operation + " is not possible because you have unmerged files; fix conflicts and add them first", Paths: paths}
+	}
+
+	return nil
+}
@@ -0,0 +1,226 @@
+package checkout
+
+import (
+	"errors"
+	"fmt"
+	"git-light/application/tag"
+	"git-light/util"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Migrate rewrites every commit reachable from branches, tags, detached HEAD, merge state
+// and stash entries with the current commit hashing scheme. parents are rewritten first so
+// that new hashes of children cover new hashes of their parents, then refs are updated.
+// it is refused while a revert, cherry-pick or rebase is in progress.
+func (cs commitService) Migrate() error {
+	rewritten := make(map[string]string)
+	inProgress := make(map[string]bool)
+
+	head, err := cs.readHead()
+	if err != nil {
+		return err
+	}
+	for _, op := range pickOperations {
+		if op.inProgress() {
+			return errors.New("a " + op.name + " is in progress. continue or abort it before migrating")
+		}
+	}
+	if _, err := os.Stat(filepath.Join(util.BaseFilePath, util.SequencerFolder)); err == nil {
+		return errors.New("a cherry-pick is in progress. continue or abort it before migrating")
+	}
+	if cs.isRebasing() {
+		return errors.New("a rebase is in progress. continue or abort it before migrating")
+	}
+	branchesDir := filepath.Join(util.BaseFilePath, util.BranchFolder)
+	branchFiles, err := cs.repo.ListAllFiles(branchesDir)
+	if err != nil {
+		return fmt.Errorf("couldn't get list of branches: %w", err)
+	}
+
+	refFiles := append(branchFiles, filepath.Join(util.BaseFilePath, util.MergeHead), filepath.Join(util.BaseFilePath, util.Stash))
+	if head.IsDetached() {
+		refFiles = append(refFiles, filepath.Join(util.BaseFilePath, util.Head))
+	}
+	tagNames, err := cs.refs.ListTags()
+	if err != nil {
+		return fmt.Errorf("couldn't get list of tags: %w", err)
+	}
+	for _, tagName := range tagNames {
+		refFiles = append(refFiles, filepath.Join(util.BaseFilePath, util.TagFolder, tagName))
+	}
+
+	for _, refFile := range refFiles {
+		lines, err := cs.repo.GetFileLines(refFile)
+		if err != nil || lines[0] == "nil" {
+			continue
+		}
+
+		newHash, err := cs.migrateObject(lines[0], rewritten, inProgress)
+		if err != nil {
+			return err
+		}
+		if newHash == lines[0] {
+			continue
+		}
+		err = cs.repo.WriteToFile(refFile, []string{newHash})
+		if err != nil {
+			return fmt.Errorf("failed to update ref %s: %w", refFile, err)
+		}
+	}
+
+	stashEntries, err := cs.refs.ReadReflog(util.Stash)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read stash entries: %w", err)
+	}
+	for _, entry := range stashEntries {
+		_, err = cs.migrateCommit(entry.NewHash, rewritten, inProgress)
+		if err != nil {
+			return err
+		}
+	}
+
+	stagedCommit, err := cs.GetStagedCommit()
+	if err == nil && rewritten[stagedCommit.PreviousCommit] != "" {
+		stagedCommit.PreviousCommit = rewritten[stagedCommit.PreviousCommit]
+		err = cs.repo.CompressAndSaveToFile(stagedCommit, filepath.Join(util.BaseFilePath, util.StageFolder, "commit"))
+		if err != nil {
+			return fmt.Errorf("failed to update staged commit: %w", err)
+		}
+	}
+
+	err = cs.migrateReflogs(rewritten)
+	if err != nil {
+		return err
+	}
+
+	var count = 0
+	for oldHash, newHash := range rewritten {
+		if oldHash == newHash {
+			continue
+		}
+		count++
+		err = cs.repo.DeleteFiles(filepath.Join(util.BaseFilePath, util.ObjectFolder, oldHash))
+		if err != nil {
+			return fmt.Errorf("failed to remove migrated commit %s: %w", oldHash, err)
+		}
+	}
+
+	fmt.Printf("migrated %d objects to commit version %d\n", count, CurrentCommitVersion)
+
+	return nil
+}
+
+// migrateObject migrates the commit given ref points to, annotated tag objects are
+// rewritten to point to the migrated commit.
+func (cs commitService) migrateObject(objectHash string, rewritten map[string]string, inProgress map[string]bool) (string, error) {
+	var tagObject tag.Tag
+	err := cs.repo.DecompressFromFileAndConvert(filepath.Join(util.BaseFilePath, util.ObjectFolder, objectHash), &tagObject)
+	if err != nil || tagObject.Object == "" {
+		return cs.migrateCommit(objectHash, rewritten, inProgress)
+	}
+	if newHash, ok := rewritten[objectHash]; ok {
+		return newHash, nil
+	}
+
+	newObject, err := cs.migrateObject(tagObject.Object, rewritten, inProgress)
+	if err != nil {
+		return "", err
+	}
+	if newObject == tagObject.Object {
+		rewritten[objectHash] = objectHash
+		return objectHash, nil
+	}
+
+	tagObject.Object = newObject
+	newHash := tagObject.CalculateHashForTag()
+	err = cs.repo.CompressAndSaveToFile(tagObject, filepath.Join(util.BaseFilePath, util.ObjectFolder, newHash))
+	if err != nil {
+		return "", fmt.Errorf("failed to save migrated tag %s: %w", newHash, err)
+	}
+
+	rewritten[objectHash] = newHash
+	return newHash, nil
+}
+
+// migrateCommit rewrites given commit after its parents. legacy hashes don't cover commit
+// metadata, so committing a tree again overwrote the earlier commit with that tree and
+// could make a commit its own ancestor. such a parent is dropped to cut the cycle, since
+// the commit it stood for no longer exists. commits being migrated are kept in inProgress
+// to notice that.
+func (cs commitService) migrateCommit(commitHash string, rewritten map[string]string, inProgress map[string]bool) (string, error) {
+	if newHash, ok := rewritten[commitHash]; ok {
+		return newHash, nil
+	}
+	inProgress[commitHash] = true
+	defer delete(inProgress, commitHash)
+
+	commit, err := cs.findCommit(commitHash)
+	if err != nil {
+		return "", err
+	}
+	parents := make([]string, 0)
+	var parentsChanged = false
+	for _, parent := range commit.GetParents() {
+		if inProgress[parent] {
+			fmt.Println("commit " + commitHash + " is its own ancestor through " + parent + ", dropping that parent")
+			parentsChanged = true
+			continue
+		}
+		newParent, err := cs.migrateCommit(parent, rewritten, inProgress)
+		if err != nil {
+			return "", err
+		}
+		parentsChanged = parentsChanged || newParent != parent
+		parents = append(parents, newParent)
+	}
+
+	if commit.Version == CurrentCommitVersion && !parentsChanged {
+		rewritten[commitHash] = commitHash
+		return commitHash, nil
+	}
+
+	commit.Version = CurrentCommitVersion
+	commit.Parents = parents
+	commit.PreviousCommit = "nil"
+	if len(parents) > 0 {
+		commit.PreviousCommit = parents[0]
+	}
+
+	newHash := commit.CalculateHashForCommit()
+	err = cs.repo.CompressAndSaveToFile(commit, filepath.Join(util.BaseFilePath, util.ObjectFolder, newHash))
+	if err != nil {
+		return "", fmt.Errorf("failed to save migrated commit %s: %w", newHash, err)
+	}
+
+	rewritten[commitHash] = newHash
+	return newHash, nil
+}
+
+// migrateReflogs replaces rewritten commit hashes in every reflog, so that reflog entries
+// keep pointing to existing commits.
+func (cs commitService) migrateReflogs(rewritten map[string]string) error {
+	logFiles, err := cs.repo.ListAllFiles(filepath.Join(util.BaseFilePath, util.LogFolder))
+	if err != nil {
+		return nil
+	}
+
+	for _, logFile := range logFiles {
+		lines, err := cs.repo.GetFileLines(logFile)
+		if err != nil {
+			return fmt.Errorf("failed to read reflog %s: %w", logFile, err)
+		}
+		for i := range lines {
+			for oldHash, newHash := range rewritten {
+				lines[i] = strings.ReplaceAll(lines[i], oldHash, newHash)
+			}
+		}
+		err = cs.repo.WriteToFile(logFile, lines)
+		if err != nil {
+			return fmt.Errorf("failed to update reflog %s: %w", logFile, err)
+		}
+	}
+
+	return nil
+}
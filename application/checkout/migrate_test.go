@@ -0,0 +1,58 @@
+package checkout
+
+import (
+	"git-light/util"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestMigrateRepeatedTree(t *testing.T) {
+	cs := setupCommitService(t)
+
+	// legacy hashes cover the tree only, so the third commit overwrote the first one
+	// and made it its own grandparent.
+	one := []File{{Path: "a.txt", Hash: "1111"}}
+	two := []File{{Path: "a.txt", Hash: "2222"}}
+	first := Commit{Version: LegacyCommitVersion, PreviousCommit: "nil", Message: "one", Files: one}
+	second := Commit{Version: LegacyCommitVersion, PreviousCommit: first.CalculateHashForCommit(), Message: "two", Files: two}
+	third := Commit{Version: LegacyCommitVersion, PreviousCommit: second.CalculateHashForCommit(), Message: "one again", Files: one}
+	for _, commit := range []Commit{second, third} {
+		err := cs.repo.CompressAndSaveToFile(commit, filepath.Join(util.BaseFilePath, util.ObjectFolder, commit.CalculateHashForCommit()))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err := cs.refs.WriteBranch(util.DefaultBranchName, third.CalculateHashForCommit(), "commit")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = cs.Migrate()
+	if err != nil {
+		t.Fatalf("Migrate() returned error: %v", err)
+	}
+
+	hash, err := cs.refs.ReadBranch(util.DefaultBranchName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var messages []string
+	for hash != "nil" {
+		commit, err := cs.findCommit(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if commit.Version != CurrentCommitVersion {
+			t.Errorf("commit %q has version %d, want %d", commit.Message, commit.Version, CurrentCommitVersion)
+		}
+		if len(messages) > 2 {
+			t.Fatalf("history after migration still has a cycle: %q", messages)
+		}
+		messages = append(messages, commit.Message)
+		hash = commit.PreviousCommit
+	}
+	if want := []string{"one again", "two"}; !slices.Equal(messages, want) {
+		t.Errorf("history after migration = %q, want %q", messages, want)
+	}
+}
@@ -0,0 +1,225 @@
+package checkout
+
+import (
+	"errors"
+	"fmt"
+	"git-light/util"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// pickOperation is a command which applies changes of a single commit on top of HEAD. when
+// it stops for conflicts headFile keeps the applied commit and MERGE_MSG keeps the message
+// the result is committed with.
+type pickOperation struct {
+	name     string
+	headFile string
+}
+
+var revertOperation = pickOperation{name: "revert", headFile: util.RevertHead}
+
+var pickOperations = []pickOperation{revertOperation, cherryPickOperation, rebaseOperation}
+
+func (op pickOperation) inProgress() bool {
+	_, err := os.Stat(filepath.Join(util.BaseFilePath, op.headFile))
+	return err == nil
+}
+
+// requireCleanTree stops given operation when another one is in progress or when staged
+// or working directory changes could be overwritten by it.
+func (cs commitService) requireCleanTree(operation string) error {
+	if _, err := cs.readHead(); err != nil {
+		return err
+	}
+	if cs.isMerging() {
+		return errors.New("a merge is in progress. commit the result or abort it before " + operation)
+	}
+	for _, op := range pickOperations {
+		if op.inProgress() {
+			return errors.New("a " + op.name + " is in progress. continue or abort it before " + operation)
+		}
+	}
+	if _, err := cs.GetStagedCommit(); err == nil {
+		return errors.New("you have staged changes. commit them before " + operation)
+	}
+
+	fileStatuses, err := cs.collectStatus()
+	if err != nil {
+		return err
+	}
+	for _, fs := range fileStatuses {
+		if fs.Code == Modified || fs.Code == Deleted {
+			return errors.New("your local changes would be overwritten by " + operation + ". commit them first")
+		}
+	}
+
+	return nil
+}
+
+// applyPick merges changes between base and theirs into HEAD and commits the result with
+// given message. it is refused when untracked files would be overwritten. on conflicts
+// nothing is committed, state is saved for continuePick and a util.ConflictError is returned.
+func (cs commitService) applyPick(op pickOperation, pickedHash string, base, theirs Commit, label, message, committer string) error {
+	head, err := cs.readHead()
+	if err != nil {
+		return err
+	}
+	oursHash := head.Hash
+	if oursHash == "nil" {
+		return errors.New("current branch does not have any commits to " + op.name + " onto")
+	}
+	ours, err := cs.findCommit(oursHash)
+	if err != nil {
+		return err
+	}
+
+	err = cs.requireNoUntrackedOverwrite(base, ours, theirs, op.name)
+	if err != nil {
+		return err
+	}
+	stageCommit, conflicts, err := cs.mergeTrees(base, ours, theirs, label)
+	if err != nil {
+		return err
+	}
+	stageCommit.PreviousCommit = oursHash
+	if len(conflicts) == 0 && maps.Equal(stageCommit.GetAllFilePaths(), ours.GetAllFilePaths()) {
+		fmt.Println("nothing to commit, changes of " + shortHash(pickedHash) + " are already applied")
+		return cs.clearStage()
+	}
+
+	err = cs.repo.CompressAndSaveToFile(stageCommit, filepath.Join(util.BaseFilePath, util.StageFolder, "commit"))
+	if err != nil {
+		return fmt.Errorf("failed to save %s result to stage: %w", op.name, err)
+	}
+
+	if len(conflicts) > 0 {
+		err = cs.savePickState(op, pickedHash, message)
+		if err != nil {
+			return err
+		}
+		return util.ConflictError{Message: fmt.Sprintf("could not %s %s; fix conflicts, add them and run '%s --continue'", op.name, shortHash(pickedHash), op.name), Paths: conflicts}
+	}
+
+	return cs.CommitChanges(message, committer)
+}
+
+// continuePick commits the resolved result of a stopped operation with its saved message.
+func (cs commitService) continuePick(op pickOperation, committer string) error {
+	if !op.inProgress() {
+		return errors.New("there is no " + op.name + " in progress")
+	}
+	err := cs.requireMerged("continuing " + op.name)
+	if err != nil {
+		return err
+	}
+
+	lines, err := cs.repo.GetFileLines(filepath.Join(util.BaseFilePath, util.MergeMessage))
+	if err != nil {
+		return fmt.Errorf("failed to read saved %s message: %w", op.name, err)
+	}
+
+	if _, err := cs.GetStagedCommit(); err != nil {
+		fmt.Println("nothing to commit, " + op.name + " resolved to no changes")
+		return cs.clearPickState()
+	}
+
+	return cs.CommitChanges(strings.Join(lines, "\n"), committer)
+}
+
+// abortPick restores HEAD in staging area and working directory and drops saved state.
+func (cs commitService) abortPick(op pickOperation) error {
+	headLines, err := cs.repo.GetFileLines(filepath.Join(util.BaseFilePath, op.headFile))
+	if err != nil || len(headLines) == 0 {
+		return errors.New("there is no " + op.name + " in progress")
+	}
+
+	picked, err := cs.findCommit(headLines[0])
+	if err != nil {
+		return err
+	}
+	touchedFiles := picked.GetAllFilePaths()
+	for _, parent := range picked.GetParents() {
+		parentCommit, err := cs.findCommit(parent)
+		if err != nil {
+			return err
+		}
+		maps.Copy(touchedFiles, parentCommit.GetAllFilePaths())
+	}
+
+	err = cs.restoreHead(touchedFiles)
+	if err != nil {
+		return err
+	}
+
+	return cs.clearPickState()
+}
+
+// restoreHead writes HEAD tree over given files and staged files, files missing in HEAD
+// are removed and staging area and unmerged paths are cleared.
+func (cs commitService) restoreHead(touchedFiles map[string]string) error {
+	stagedCommit, err := cs.GetStagedCommit()
+	if err == nil {
+		maps.Copy(touchedFiles, stagedCommit.GetAllFilePaths())
+	}
+
+	ours, err := cs.GetLastCommitOnCurrentBranch()
+	if err != nil {
+		return err
+	}
+	oursFiles := ours.GetAllFilePaths()
+	for path := range touchedFiles {
+		if _, ok := oursFiles[path]; !ok {
+			err = cs.removeWorkingFile(path)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for path, hash := range oursFiles {
+		err = cs.writeBlob(path, hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = cs.clearStage()
+	if err != nil {
+		return err
+	}
+
+	return cs.clearUnmergedPaths()
+}
+
+func (cs commitService) savePickState(op pickOperation, pickedHash string, message string) error {
+	err := cs.repo.WriteToFile(filepath.Join(util.BaseFilePath, op.headFile), []string{pickedHash})
+	if err != nil {
+		return fmt.Errorf("failed to save %s state: %w", op.name, err)
+	}
+
+	err = cs.repo.WriteToFile(filepath.Join(util.BaseFilePath, util.MergeMessage), strings.Split(message, "\n"))
+	if err != nil {
+		return fmt.Errorf("failed to save %s message: %w", op.name, err)
+	}
+
+	return nil
+}
+
+// clearPickState removes state of a stopped operation, it is called after every commit so
+// that committing a resolved result directly also concludes the operation.
+func (cs commitService) clearPickState() error {
+	paths := []string{util.MergeMessage}
+	for _, op := range pickOperations {
+		paths = append(paths, op.headFile)
+	}
+
+	for _, path := range paths {
+		err := cs.repo.DeleteFiles(filepath.Join(util.BaseFilePath, path))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clear state of %s: %w", path, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,529 @@
+package checkout
+
+import (
+	"errors"
+	"fmt"
+	"git-light/util"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+var rebaseOperation = pickOperation{name: "rebase", headFile: filepath.Join(util.RebaseFolder, "stopped")}
+
+// rebase state files. head-name is the rebased branch, empty when HEAD was detached, and
+// orig-head is where it was before rebase started. todo keeps steps left to run and current
+// keeps the step which stopped for conflicts.
+var (
+	rebaseHeadNamePath = filepath.Join(util.BaseFilePath, util.RebaseFolder, "head-name")
+	rebaseOrigHeadPath = filepath.Join(util.BaseFilePath, util.RebaseFolder, "orig-head")
+	rebaseOntoPath     = filepath.Join(util.BaseFilePath, util.RebaseFolder, "onto")
+	rebaseTodoPath     = filepath.Join(util.BaseFilePath, util.RebaseFolder, "todo")
+	rebaseCurrentPath  = filepath.Join(util.BaseFilePath, util.RebaseFolder, "current")
+)
+
+// rebase todo actions, reword takes the new message after the commit.
+const (
+	rebasePick   = "pick"
+	rebaseSquash = "squash"
+	rebaseDrop   = "drop"
+	rebaseReword = "reword"
+)
+
+var rebaseActionAliases = map[string]string{"p": rebasePick, "s": rebaseSquash, "d": rebaseDrop, "r": rebaseReword}
+
+// rebaseStep is a single line of a rebase todo list.
+type rebaseStep struct {
+	action  string
+	hash    string
+	message string
+}
+
+func (step rebaseStep) String() string {
+	if step.message == "" {
+		return step.action + " " + step.hash
+	}
+
+	return step.action + " " + step.hash + " " + step.message
+}
+
+// Rebase replays commits of current branch which are not reachable from upstream on top of
+// upstream one by one and moves the branch to the result. merge commits are left out to
+// keep history linear. todoFile may list steps like "pick <rev>", "squash <rev>",
+// "drop <rev>" and "reword <rev> <message>" to run instead of picking every commit.
+func (cs commitService) Rebase(upstream string, todoFile string) error {
+	if cs.isRebasing() {
+		return errors.New("a rebase is in progress. continue, skip or abort it before rebasing")
+	}
+	err := cs.requireCleanTree("rebasing")
+	if err != nil {
+		return err
+	}
+
+	head, err := cs.readHead()
+	if err != nil {
+		return err
+	}
+	if head.Hash == "nil" {
+		return errors.New("current branch does not have any commits to rebase")
+	}
+	ontoHash, err := cs.revisions.Resolve(upstream)
+	if err != nil {
+		return err
+	}
+	ontoAncestors, err := cs.collectAncestors(ontoHash)
+	if err != nil {
+		return err
+	}
+
+	var steps []rebaseStep
+	var skippedMerges = false
+	if todoFile != "" {
+		steps, err = cs.readRebaseTodo(todoFile)
+		if err != nil {
+			return err
+		}
+		if len(steps) == 0 {
+			return errors.New("nothing to do, rebase todo is empty: " + todoFile)
+		}
+	} else {
+		commits, err := cs.collectLogCommits([]string{head.Hash}, ontoAncestors)
+		if err != nil {
+			return err
+		}
+		order, err := orderLogCommits([]string{head.Hash}, commits)
+		if err != nil {
+			return err
+		}
+		slices.Reverse(order)
+		for _, hash := range order {
+			if len(commits[hash].GetParents()) > 1 {
+				fmt.Println("skipping merge commit " + shortHash(hash))
+				skippedMerges = true
+				continue
+			}
+			steps = append(steps, rebaseStep{action: rebasePick, hash: hash})
+		}
+	}
+
+	headCommit, err := cs.findCommit(head.Hash)
+	if err != nil {
+		return err
+	}
+	onto, err := cs.findCommit(ontoHash)
+	if err != nil {
+		return err
+	}
+	err = cs.requireNoUntrackedOverwrite(headCommit, headCommit, onto, "rebase")
+	if err != nil {
+		return err
+	}
+
+	if len(steps) == 0 && ontoAncestors[head.Hash] && head.Hash != ontoHash {
+		err = cs.checkoutTree(headCommit.GetAllFilePaths(), onto.GetAllFilePaths(), false)
+		if err != nil {
+			return err
+		}
+		err = cs.updateHead(ontoHash, "rebase: fast-forward to "+upstream)
+		if err != nil {
+			return err
+		}
+		fmt.Println("Fast-forwarded to " + upstream + ".")
+		return nil
+	}
+	if todoFile == "" && !skippedMerges {
+		headAncestors, err := cs.collectAncestors(head.Hash)
+		if err != nil {
+			return err
+		}
+		if headAncestors[ontoHash] {
+			fmt.Println("Current branch is up to date.")
+			return nil
+		}
+	}
+
+	for path, content := range map[string]string{rebaseHeadNamePath: head.Branch, rebaseOrigHeadPath: head.Hash, rebaseOntoPath: ontoHash} {
+		err = cs.repo.WriteToFile(path, []string{content})
+		if err != nil {
+			return fmt.Errorf("failed to save rebase state: %w", err)
+		}
+	}
+
+	err = cs.checkoutTree(headCommit.GetAllFilePaths(), onto.GetAllFilePaths(), false)
+	if err != nil {
+		return err
+	}
+	err = cs.refs.DetachHead(ontoHash, "rebase: checkout "+upstream)
+	if err != nil {
+		return fmt.Errorf("failed to update HEAD: %w", err)
+	}
+
+	return cs.runRebase(steps)
+}
+
+// ContinueRebase commits the resolved conflict of a stopped step and runs remaining steps.
+func (cs commitService) ContinueRebase() error {
+	if !cs.isRebasing() {
+		return errors.New("there is no rebase in progress")
+	}
+
+	if rebaseOperation.inProgress() {
+		step, err := cs.currentRebaseStep()
+		if err != nil {
+			return err
+		}
+		head, err := cs.readHead()
+		if err != nil {
+			return err
+		}
+		picked, err := cs.findCommit(step.hash)
+		if err != nil {
+			return err
+		}
+		err = cs.continuePick(rebaseOperation, picked.Committer)
+		if err != nil {
+			return err
+		}
+		err = cs.squashIfCommitted(step, head.Hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	steps, err := cs.readRebaseSteps(rebaseTodoPath)
+	if err != nil {
+		return err
+	}
+
+	return cs.runRebase(steps)
+}
+
+// SkipRebase drops the step which stopped for conflicts and runs remaining steps.
+func (cs commitService) SkipRebase() error {
+	if !cs.isRebasing() {
+		return errors.New("there is no rebase in progress")
+	}
+
+	if rebaseOperation.inProgress() {
+		err := cs.abortPick(rebaseOperation)
+		if err != nil {
+			return err
+		}
+	}
+
+	steps, err := cs.readRebaseSteps(rebaseTodoPath)
+	if err != nil {
+		return err
+	}
+
+	return cs.runRebase(steps)
+}
+
+// AbortRebase drops a stopped rebase and checks out the branch as it was before rebase.
+func (cs commitService) AbortRebase() error {
+	if !cs.isRebasing() {
+		return errors.New("there is no rebase in progress")
+	}
+
+	if rebaseOperation.inProgress() {
+		err := cs.abortPick(rebaseOperation)
+		if err != nil {
+			return err
+		}
+	}
+
+	origHead, err := cs.readRebaseState(rebaseOrigHeadPath)
+	if err != nil {
+		return err
+	}
+	head, err := cs.readHead()
+	if err != nil {
+		return err
+	}
+	if head.Hash != origHead {
+		err = cs.Reset(origHead, ResetHard)
+		if err != nil {
+			return err
+		}
+	}
+
+	branchName, err := cs.readRebaseState(rebaseHeadNamePath)
+	if err != nil {
+		return err
+	}
+	if branchName != "" {
+		err = cs.refs.AttachHead(branchName, "rebase (abort): returning to "+branchName)
+		if err != nil {
+			return fmt.Errorf("failed to update HEAD: %w", err)
+		}
+	}
+
+	return cs.clearRebaseState()
+}
+
+// runRebase runs given steps on top of HEAD, remaining steps are saved before each step so
+// that a step stopped for conflicts can be continued. when all steps are done the rebased
+// branch is moved to HEAD.
+func (cs commitService) runRebase(steps []rebaseStep) error {
+	for i, step := range steps {
+		todo := make([]string, 0, len(steps)-i-1)
+		for _, next := range steps[i+1:] {
+			todo = append(todo, next.String())
+		}
+		err := cs.repo.WriteToFile(rebaseTodoPath, todo)
+		if err != nil {
+			return fmt.Errorf("failed to save rebase state: %w", err)
+		}
+		err = cs.repo.WriteToFile(rebaseCurrentPath, []string{step.String()})
+		if err != nil {
+			return fmt.Errorf("failed to save rebase state: %w", err)
+		}
+
+		err = cs.runRebaseStep(step)
+		if err != nil && !rebaseOperation.inProgress() {
+			// nothing was applied, keep the step in todo so that continue runs it again.
+			if saveErr := cs.repo.WriteToFile(rebaseTodoPath, append([]string{step.String()}, todo...)); saveErr != nil {
+				return fmt.Errorf("failed to save rebase state: %w", saveErr)
+			}
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	head, err := cs.readHead()
+	if err != nil {
+		return err
+	}
+	branchName, err := cs.readRebaseState(rebaseHeadNamePath)
+	if err != nil {
+		return err
+	}
+	if branchName == "" {
+		fmt.Println("Successfully rebased HEAD.")
+		return cs.clearRebaseState()
+	}
+
+	onto, err := cs.readRebaseState(rebaseOntoPath)
+	if err != nil {
+		return err
+	}
+	err = cs.refs.WriteBranch(branchName, head.Hash, "rebase (finish): "+branchName+" onto "+onto)
+	if err != nil {
+		return fmt.Errorf("failed to update branch: %w", err)
+	}
+	err = cs.refs.AttachHead(branchName, "rebase (finish): returning to "+branchName)
+	if err != nil {
+		return fmt.Errorf("failed to update HEAD: %w", err)
+	}
+	err = cs.clearRebaseState()
+	if err != nil {
+		return err
+	}
+	fmt.Println("Successfully rebased and updated " + branchName + ".")
+
+	return nil
+}
+
+// runRebaseStep applies a single step, a util.ConflictError is returned when it stopped
+// for conflicts. a pick whose parent is HEAD already reuses the commit as it is.
+func (cs commitService) runRebaseStep(step rebaseStep) error {
+	if step.action == rebaseDrop {
+		return nil
+	}
+
+	head, err := cs.readHead()
+	if err != nil {
+		return err
+	}
+	picked, err := cs.findCommit(step.hash)
+	if err != nil {
+		return err
+	}
+	var parent Commit
+	parents := picked.GetParents()
+	if len(parents) > 0 {
+		parent, err = cs.findCommit(parents[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	if step.action == rebasePick && len(parents) > 0 && parents[0] == head.Hash {
+		err = cs.requireNoUntrackedOverwrite(parent, parent, picked, "rebase")
+		if err != nil {
+			return err
+		}
+		err = cs.checkoutTree(parent.GetAllFilePaths(), picked.GetAllFilePaths(), false)
+		if err != nil {
+			return err
+		}
+		return cs.updateHead(step.hash, "rebase (pick): fast-forward")
+	}
+	if step.action == rebaseSquash {
+		onto, err := cs.readRebaseState(rebaseOntoPath)
+		if err != nil {
+			return err
+		}
+		if head.Hash == onto {
+			return errors.New("can not squash " + shortHash(step.hash) + " without a previous commit, use rebase --abort")
+		}
+	}
+
+	message := picked.Message
+	if step.action == rebaseReword {
+		message = step.message
+	}
+
+	subject, _, _ := strings.Cut(picked.Message, "\n")
+	err = cs.applyPick(rebaseOperation, step.hash, parent, picked, shortHash(step.hash)+" ("+subject+")", message, picked.Committer)
+	if err != nil {
+		return err
+	}
+
+	return cs.squashIfCommitted(step, head.Hash)
+}
+
+// squashIfCommitted squashes the commit a squash step created, nothing is done when the
+// step didn't move HEAD away from given previous head.
+func (cs commitService) squashIfCommitted(step rebaseStep, previousHead string) error {
+	if step.action != rebaseSquash {
+		return nil
+	}
+	head, err := cs.readHead()
+	if err != nil || head.Hash == previousHead {
+		return err
+	}
+
+	return cs.squashHead()
+}
+
+// squashHead folds HEAD into its parent, messages of both commits are kept and the parent's
+// committer is used for the result.
+func (cs commitService) squashHead() error {
+	headRef, err := cs.readHead()
+	if err != nil {
+		return err
+	}
+	headHash := headRef.Hash
+	head, err := cs.findCommit(headHash)
+	if err != nil {
+		return err
+	}
+	target, err := cs.findCommit(head.GetParents()[0])
+	if err != nil {
+		return err
+	}
+	targetParents := target.GetParents()
+	if len(targetParents) == 0 {
+		return errors.New("can not squash into root commit " + head.GetParents()[0])
+	}
+
+	err = cs.updateHead(targetParents[0], "rebase (squash): "+shortHash(headHash))
+	if err != nil {
+		return err
+	}
+	err = cs.saveStage(Commit{PreviousCommit: targetParents[0], Files: slices.Clone(head.Files)})
+	if err != nil {
+		return err
+	}
+
+	return cs.CommitChanges(target.Message+"\n\n"+head.Message, target.Committer)
+}
+
+// readRebaseTodo reads steps from a user given todo file, empty lines and lines starting
+// with # are skipped and revisions are resolved to commit hashes.
+func (cs commitService) readRebaseTodo(todoFile string) ([]rebaseStep, error) {
+	steps, err := cs.readRebaseSteps(todoFile)
+	if err != nil {
+		return nil, err
+	}
+	for i, step := range steps {
+		steps[i].hash, err = cs.revisions.Resolve(step.hash)
+		if err != nil {
+			return nil, err
+		}
+		if step.action == rebaseReword && step.message == "" {
+			return nil, errors.New("reword needs a message: " + step.String())
+		}
+		if step.action != rebaseReword {
+			steps[i].message = ""
+		}
+	}
+
+	return steps, nil
+}
+
+func (cs commitService) readRebaseSteps(path string) ([]rebaseStep, error) {
+	lines, err := cs.repo.GetFileLines(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rebase todo %s: %w", path, err)
+	}
+
+	steps := make([]rebaseStep, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(line, " ", 3)
+		if len(fields) < 2 {
+			return nil, errors.New("invalid rebase todo line: " + line)
+		}
+		action := fields[0]
+		if alias, ok := rebaseActionAliases[action]; ok {
+			action = alias
+		}
+		if !slices.Contains([]string{rebasePick, rebaseSquash, rebaseDrop, rebaseReword}, action) {
+			return nil, errors.New("unknown rebase action: " + fields[0])
+		}
+
+		step := rebaseStep{action: action, hash: fields[1]}
+		if len(fields) == 3 {
+			step.message = strings.TrimSpace(fields[2])
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+func (cs commitService) currentRebaseStep() (rebaseStep, error) {
+	steps, err := cs.readRebaseSteps(rebaseCurrentPath)
+	if err != nil {
+		return rebaseStep{}, err
+	}
+	if len(steps) != 1 {
+		return rebaseStep{}, errors.New("failed to read current rebase step")
+	}
+
+	return steps[0], nil
+}
+
+func (cs commitService) readRebaseState(path string) (string, error) {
+	lines, err := cs.repo.GetFileLines(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read rebase state %s: %w", path, err)
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+
+	return lines[0], nil
+}
+
+func (cs commitService) isRebasing() bool {
+	_, err := os.Stat(filepath.Join(util.BaseFilePath, util.RebaseFolder))
+	return err == nil
+}
+
+func (cs commitService) clearRebaseState() error {
+	err := os.RemoveAll(filepath.Join(util.BaseFilePath, util.RebaseFolder))
+	if err != nil {
+		return fmt.Errorf("failed to clear rebase state: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package checkout
+
+import (
+	"errors"
+	"fmt"
+	"git-light/util"
+	"os"
+	"path/filepath"
+)
+
+// Reflog prints movements of given ref newest first, HEAD is used when ref is empty.
+func (cs commitService) Reflog(refName string) error {
+	if _, err := cs.readHead(); err != nil {
+		return err
+	}
+	if refName == "" {
+		refName = util.Head
+	}
+	if refName != util.Head && refName != util.Stash && !cs.refs.BranchExists(refName) {
+		return errors.New("no such ref: " + refName)
+	}
+
+	entries, err := cs.refs.ReadReflog(refName)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read reflog of %s: %w", refName, err)
+	}
+
+	color := util.IsTerminal(os.Stdout)
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		fmt.Printf("%s %s@{%d}: %s\n", colorize(color, "\033[33m", entry.NewHash), refName, len(entries)-1-i, entry.Reason)
+	}
+
+	return nil
+}
+
+// warnUnreachable prints commits which can't be reached from any branch once HEAD leaves
+// given commit, they would be lost unless a branch is created for them.
+func (cs commitService) warnUnreachable(commitHash string) error {
+	commits, err := cs.unreachableCommits(commitHash)
+	if err != nil || len(commits) == 0 {
+		return err
+	}
+
+	fmt.Printf("Warning: you are leaving %d commit(s) behind, not connected to any of your branches:\n\n", len(commits))
+	for _, hash := range commits {
+		commit, err := cs.findCommit(hash)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("  %s %s\n", hash, commit.Message)
+	}
+	fmt.Printf("\nIf you want to keep them, create a new branch for them with:\n\n git-light branch <new-branch-name> %s\n\n", commitHash)
+
+	return nil
+}
+
+// unreachableCommits returns given commit and its ancestors which are not reachable
+// from any branch, newest first.
+func (cs commitService) unreachableCommits(commitHash string) ([]string, error) {
+	tips, err := cs.branchTips()
+	if err != nil {
+		return nil, err
+	}
+	reachable := make(map[string]bool)
+	for _, tip := range tips {
+		ancestors, err := cs.collectAncestors(tip)
+		if err != nil {
+			return nil, err
+		}
+		for hash := range ancestors {
+			reachable[hash] = true
+		}
+	}
+
+	unreachable := make([]string, 0)
+	visited := make(map[string]bool)
+	queue := []string{commitHash}
+	for len(queue) > 0 {
+		hash := queue[0]
+		queue = queue[1:]
+		if visited[hash] || reachable[hash] || hash == "nil" {
+			continue
+		}
+		visited[hash] = true
+
+		unreachable = append(unreachable, hash)
+		commit, err := cs.findCommit(hash)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, commit.GetParents()...)
+	}
+
+	return unreachable, nil
+}
+
+// branchTips returns commit hashes that branches point to, empty branches are left out.
+func (cs commitService) branchTips() ([]string, error) {
+	branchesDir := filepath.Join(util.BaseFilePath, util.BranchFolder)
+	branchFiles, err := cs.repo.ListAllFiles(branchesDir)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get list of branches: %w", err)
+	}
+
+	tips := make([]string, 0)
+	for _, branchFile := range branchFiles {
+		branchName, _ := filepath.Rel(branchesDir, branchFile)
+		commitHash, err := cs.refs.ReadBranch(branchName)
+		if err == nil && commitHash != "nil" {
+			tips = append(tips, commitHash)
+		}
+	}
+
+	return tips, nil
+}
@@ -0,0 +1,97 @@
+package checkout
+
+import (
+	"slices"
+	"strings"
+)
+
+// RenameSimilarity is the minimum similarity percentage for a deleted and an added file
+// to be reported as a rename.
+const RenameSimilarity = 50
+
+type rename struct {
+	From       string
+	To         string
+	Similarity int
+}
+
+// detectRenames pairs files which exist only on the from side with files which exist only
+// on the to side. identical blobs are always paired, text files are paired when their
+// similarity computed from the Myers edit script reaches RenameSimilarity. the most
+// similar pairs are chosen first and each file takes part in at most one rename.
+func (cs commitService) detectRenames(from, to diffSide) ([]rename, error) {
+	deleted := make([]string, 0)
+	for _, path := range sortedPaths(from.files) {
+		if _, ok := to.files[path]; !ok {
+			deleted = append(deleted, path)
+		}
+	}
+	added := make([]string, 0)
+	for _, path := range sortedPaths(to.files) {
+		if _, ok := from.files[path]; !ok {
+			added = append(added, path)
+		}
+	}
+	if len(deleted) == 0 || len(added) == 0 {
+		return []rename{}, nil
+	}
+
+	contents := make(map[string][]byte)
+	load := func(side diffSide, prefix string, path string) ([]byte, error) {
+		if _, ok := contents[prefix+path]; !ok {
+			content, err := side.load(path)
+			if err != nil {
+				return nil, err
+			}
+			contents[prefix+path] = content
+		}
+		return contents[prefix+path], nil
+	}
+
+	candidates := make([]rename, 0)
+	for _, fromPath := range deleted {
+		for _, toPath := range added {
+			if from.files[fromPath] == to.files[toPath] {
+				candidates = append(candidates, rename{From: fromPath, To: toPath, Similarity: 100})
+				continue
+			}
+
+			src, err := load(from, "a/", fromPath)
+			if err != nil {
+				return nil, err
+			}
+			dst, err := load(to, "b/", toPath)
+			if err != nil {
+				return nil, err
+			}
+			if isBinary(src) || isBinary(dst) {
+				continue
+			}
+			similarity := cs.myers.Similarity(splitLines(src), splitLines(dst))
+			if similarity >= RenameSimilarity {
+				candidates = append(candidates, rename{From: fromPath, To: toPath, Similarity: similarity})
+			}
+		}
+	}
+
+	slices.SortStableFunc(candidates, func(a, b rename) int {
+		return b.Similarity - a.Similarity
+	})
+
+	renames := make([]rename, 0)
+	paired := make(map[string]bool)
+	for _, candidate := range candidates {
+		if paired["a/"+candidate.From] || paired["b/"+candidate.To] {
+			continue
+		}
+		paired["a/"+candidate.From] = true
+		paired["b/"+candidate.To] = true
+		renames = append(renames, candidate)
+	}
+
+	slices.SortFunc(renames, func(a, b rename) int {
+		return strings.Compare(a.To, b.To)
+	})
+
+	return renames, nil
+}
@@ -0,0 +1,163 @@
+package checkout
+
+import (
+	"errors"
+	"fmt"
+	"git-light/util"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// ResetMode tells Reset which of branch, staging area and working directory are moved to
+// the target commit.
+type ResetMode string
+
+const (
+	// ResetSoft moves only the branch, staged changes and working directory are kept.
+	ResetSoft ResetMode = "soft"
+	// ResetMixed moves the branch and staging area, working directory is kept.
+	ResetMixed ResetMode = "mixed"
+	// ResetHard moves the branch, staging area and working directory, changes are lost.
+	ResetHard ResetMode = "hard"
+)
+
+// Reset moves current branch, or HEAD when detached, to given revision. what happens to
+// staging area and working directory depends on mode.
+func (cs commitService) Reset(rev string, mode ResetMode) error {
+	if rev == "" {
+		rev = util.Head
+	}
+	if mode == ResetSoft && cs.isMerging() {
+		return errors.New("can not do a soft reset in the middle of a merge")
+	}
+
+	targetHash, err := cs.revisions.Resolve(rev)
+	if err != nil {
+		return err
+	}
+	target, err := cs.findCommit(targetHash)
+	if err != nil {
+		return err
+	}
+
+	current, err := cs.GetLastCommitOnCurrentBranch()
+	if err != nil && !errors.Is(err, errEmptyBranch) {
+		return err
+	}
+	staged, stagedErr := cs.GetStagedCommit()
+	if stagedErr != nil {
+		staged = current
+	}
+
+	switch mode {
+	case ResetSoft:
+		stageCommit := Commit{PreviousCommit: targetHash, Files: slices.Clone(staged.Files)}
+		err = cs.updateHead(targetHash, "reset: moving to "+rev)
+		if err != nil {
+			return err
+		}
+		return cs.saveStage(stageCommit)
+	case ResetMixed:
+		err = cs.updateHead(targetHash, "reset: moving to "+rev)
+		if err != nil {
+			return err
+		}
+		return cs.clearResetState()
+	case ResetHard:
+		trackedFiles := current.GetAllFilePaths()
+		maps.Copy(trackedFiles, staged.GetAllFilePaths())
+		err = cs.checkoutTree(trackedFiles, target.GetAllFilePaths(), true)
+		if err != nil {
+			return err
+		}
+		err = cs.updateHead(targetHash, "reset: moving to "+rev)
+		if err != nil {
+			return err
+		}
+		err = cs.clearResetState()
+		if err != nil {
+			return err
+		}
+		subject, _, _ := strings.Cut(target.Message, "\n")
+		fmt.Println("HEAD is now at " + shortHash(targetHash) + " " + subject)
+		return nil
+	default:
+		return errors.New("unknown reset mode: " + string(mode))
+	}
+}
+
+// ResetPaths unstages given paths by setting their staged versions back to the ones in given
+// revision, or HEAD. working directory is not touched.
+func (cs commitService) ResetPaths(rev string, paths []string) error {
+	if rev == "" {
+		rev = util.Head
+	}
+
+	head, err := cs.readHead()
+	if err != nil {
+		return err
+	}
+	var target Commit
+	if rev != util.Head || head.Hash != "nil" {
+		targetHash, err := cs.revisions.Resolve(rev)
+		if err != nil {
+			return err
+		}
+		target, err = cs.findCommit(targetHash)
+		if err != nil {
+			return err
+		}
+	}
+	stageCommit, err := cs.loadStage()
+	if err != nil {
+		return err
+	}
+
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		stagedPaths := stageCommit.GetFilesUnder(path)
+		targetPaths := target.GetFilesUnder(path)
+		if len(stagedPaths) == 0 && len(targetPaths) == 0 {
+			return errors.New("path did not match any files: " + path)
+		}
+
+		for _, stagedPath := range stagedPaths {
+			stageCommit.RemoveFile(stagedPath)
+		}
+		for _, targetPath := range targetPaths {
+			stageCommit.SetFile(target.GetFile(targetPath))
+		}
+	}
+
+	return cs.saveStage(stageCommit)
+}
+
+// clearResetState empties staging area and drops an ongoing merge, revert or cherry-pick,
+// staged blobs are removed with it since nothing refers to them anymore. a stopped rebase
+// keeps its state and message, so that it can still be continued, skipped or aborted.
+func (cs commitService) clearResetState() error {
+	err := cs.clearStage()
+	if err != nil {
+		return err
+	}
+
+	err = cs.repo.DeleteFiles(filepath.Join(util.BaseFilePath, util.MergeHead))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear merge state: %w", err)
+	}
+	if !rebaseOperation.inProgress() {
+		err = cs.clearPickState()
+		if err != nil {
+			return err
+		}
+	}
+	err = cs.clearSequencer()
+	if err != nil {
+		return err
+	}
+
+	return cs.clearUnmergedPaths()
+}
@@ -0,0 +1,66 @@
+package checkout
+
+import (
+	"git-light/util"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// commitFile writes given content to a file and commits it.
+func commitFile(t *testing.T, cs commitService, path string, content string) {
+	t.Helper()
+
+	writeWorkingFile(t, path, content)
+	err := cs.AddToStage([]string{path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cs.CommitChanges("change "+path, "tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResetKeepsStoppedRebase(t *testing.T) {
+	cs := setupCommitService(t)
+
+	commitFile(t, cs, "a.txt", "base\n")
+	base, err := cs.refs.ReadBranch(util.DefaultBranchName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, cs, "a.txt", "main\n")
+	err = cs.refs.WriteBranch("side", base, "branch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cs.Checkout("side", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, cs, "a.txt", "side\n")
+
+	err = cs.Rebase(util.DefaultBranchName, "")
+	if err == nil {
+		t.Fatal("Rebase() of conflicting changes returned no error")
+	}
+	err = cs.Reset(util.Head, ResetHard)
+	if err != nil {
+		t.Fatalf("Reset() returned error: %v", err)
+	}
+	if !rebaseOperation.inProgress() {
+		t.Fatal("Reset() dropped state of the stopped rebase")
+	}
+	if _, err := os.Stat(filepath.Join(util.BaseFilePath, util.MergeMessage)); err != nil {
+		t.Fatalf("Reset() dropped message of the stopped rebase: %v", err)
+	}
+
+	err = cs.ContinueRebase()
+	if err != nil {
+		t.Fatalf("ContinueRebase() after Reset() returned error: %v", err)
+	}
+	if cs.isRebasing() {
+		t.Error("rebase is still in progress after ContinueRebase()")
+	}
+}
@@ -0,0 +1,49 @@
+package checkout
+
+import (
+	"errors"
+	"strings"
+)
+
+// Revert creates a commit undoing changes of given commit compared to its parent, changes
+// made since then are kept with a three-way merge. merge commits can't be reverted since
+// it is not known which parent to go back to.
+func (cs commitService) Revert(rev string, committer string) error {
+	err := cs.requireCleanTree("reverting")
+	if err != nil {
+		return err
+	}
+
+	revertedHash, err := cs.revisions.Resolve(rev)
+	if err != nil {
+		return err
+	}
+	reverted, err := cs.findCommit(revertedHash)
+	if err != nil {
+		return err
+	}
+	parents := reverted.GetParents()
+	if len(parents) > 1 {
+		return errors.New("commit " + revertedHash + " is a merge, reverting merges is not supported")
+	}
+
+	var parent Commit
+	if len(parents) == 1 {
+		parent, err = cs.findCommit(parents[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	subject, _, _ := strings.Cut(reverted.Message, "\n")
+	message := "Revert \"" + subject + "\"\n\nThis reverts commit " + revertedHash + "."
+	return cs.applyPick(revertOperation, revertedHash, reverted, parent, "parent of "+shortHash(revertedHash)+" ("+subject+")", message, committer)
+}
+
+func (cs commitService) ContinueRevert(committer string) error {
+	return cs.continuePick(revertOperation, committer)
+}
+
+func (cs commitService) AbortRevert() error {
+	return cs.abortPick(revertOperation)
+}
@@ -0,0 +1,116 @@
+package checkout
+
+import (
+	"errors"
+	"fmt"
+	"git-light/application/tag"
+	"git-light/util"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Show prints given object. <rev> prints the commit with its diff against first parent,
+// annotated tags are printed before the commit they point to. <rev>:<path> prints content
+// of a file as it is in that commit, or the entries of a directory.
+func (cs commitService) Show(object string, contextLines int) error {
+	rev, path, isBlob := strings.Cut(object, ":")
+	if rev == "" {
+		rev = util.Head
+	}
+	commitHash, err := cs.revisions.Resolve(rev)
+	if err != nil {
+		return err
+	}
+	commit, err := cs.findCommit(commitHash)
+	if err != nil {
+		return err
+	}
+
+	if isBlob {
+		return cs.showPath(rev, commit, path)
+	}
+
+	color := util.IsTerminal(os.Stdout)
+	cs.showTag(rev, color)
+
+	lines, err := cs.formatLogEntry(logEntry{hash: commitHash, commit: commit}, LogOptions{}, color)
+	if err != nil {
+		return err
+	}
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+
+	parent, err := cs.firstParent(commit)
+	if err != nil {
+		return err
+	}
+
+	return cs.printTreeDiff(cs.commitSide(parent), cs.commitSide(commit), contextLines)
+}
+
+// showTag prints header of given annotated tag, nothing is printed for other revisions.
+func (cs commitService) showTag(tagName string, color bool) {
+	objectHash, err := cs.refs.ReadTag(tagName)
+	if err != nil {
+		return
+	}
+
+	var tagObject tag.Tag
+	err = cs.repo.DecompressFromFileAndConvert(filepath.Join(util.BaseFilePath, util.ObjectFolder, objectHash), &tagObject)
+	if err != nil || tagObject.Object == "" {
+		return
+	}
+
+	fmt.Println(colorize(color, "\033[33m", "tag "+tagObject.Name))
+	fmt.Println("Tagger: " + tagObject.Tagger)
+	fmt.Println("Date:   " + tagObject.Date.Format(logDateLayout))
+	fmt.Println()
+	fmt.Println(tagObject.Message)
+	fmt.Println()
+}
+
+// showPath writes exact content of a file in given commit to stdout, for a directory its
+// entries are listed with a trailing slash on subdirectories.
+func (cs commitService) showPath(rev string, commit Commit, path string) error {
+	path = filepath.Clean(path)
+	files := commit.GetAllFilePaths()
+	if hash, ok := files[path]; ok {
+		lines, err := cs.ExtractFileFromObjectStore(hash)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(joinLines(lines))
+		if err != nil {
+			return fmt.Errorf("failed to write file content: %w", err)
+		}
+		return nil
+	}
+
+	filePaths := commit.GetFilesUnder(path)
+	if len(filePaths) == 0 {
+		return errors.New("path " + path + " does not exist in " + rev)
+	}
+
+	fmt.Printf("tree %s:%s\n\n", rev, path)
+	printed := make(map[string]bool)
+	for _, filePath := range sortedPaths(files) {
+		if indexOf(filePaths, filePath) == -1 {
+			continue
+		}
+		entry := filePath
+		if path != "." {
+			entry = strings.TrimPrefix(filePath, path+string(filepath.Separator))
+		}
+		if name, _, isDir := strings.Cut(entry, string(filepath.Separator)); isDir {
+			entry = name + "/"
+		}
+		if !printed[entry] {
+			printed[entry] = true
+			fmt.Println(entry)
+		}
+	}
+
+	return nil
+}
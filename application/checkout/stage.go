@@ -0,0 +1,191 @@
+package checkout
+
+import (
+	"errors"
+	"fmt"
+	"git-light/util"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Remove stages deletion of given files and removes them from working directory unless
+// cached is set. files whose changes aren't committed are kept unless force is set.
+func (cs commitService) Remove(filePaths []string, cached bool, force bool) error {
+	stageCommit, err := cs.loadStage()
+	if err != nil {
+		return err
+	}
+	lastFiles, err := cs.lastCommitFiles()
+	if err != nil {
+		return err
+	}
+
+	removedPaths := make([]string, 0)
+	for _, path := range filePaths {
+		path = filepath.Clean(path)
+		trackedPaths := stageCommit.GetFilesUnder(path)
+		if len(trackedPaths) == 0 {
+			return errors.New("path did not match any tracked files: " + path)
+		}
+		removedPaths = append(removedPaths, trackedPaths...)
+	}
+
+	if !cached && !force {
+		changedPaths := make([]string, 0)
+		for _, path := range removedPaths {
+			stagedHash := stageCommit.GetFile(path).Hash
+			if lastFiles[path] != stagedHash {
+				changedPaths = append(changedPaths, path)
+				continue
+			}
+
+			content, err := cs.readWorkingFile(path)
+			if err != nil {
+				continue
+			}
+			matches, err := cs.blobMatches(stagedHash, content)
+			if err != nil {
+				return err
+			}
+			if !matches {
+				changedPaths = append(changedPaths, path)
+			}
+		}
+		if len(changedPaths) > 0 {
+			return util.ConflictError{Message: "the following files have changes which are not committed. use --cached to keep them or --force to remove them", Paths: changedPaths}
+		}
+	}
+
+	for _, path := range removedPaths {
+		stageCommit.RemoveFile(path)
+		if !cached {
+			err = cs.removeWorkingFile(path)
+			if err != nil {
+				return err
+			}
+		}
+		fmt.Printf("rm '%s'\n", path)
+	}
+
+	err = cs.saveStage(stageCommit)
+	if err != nil {
+		return err
+	}
+
+	return cs.resolveUnmergedPaths(removedPaths)
+}
+
+// Move renames a tracked file or directory in working directory and stages the rename.
+// destination may be an existing directory to move source into.
+func (cs commitService) Move(source string, destination string, force bool) error {
+	source = filepath.Clean(source)
+	destination = filepath.Clean(destination)
+	if info, err := os.Stat(destination); err == nil && info.IsDir() {
+		destination = filepath.Join(destination, filepath.Base(source))
+	}
+
+	stageCommit, err := cs.loadStage()
+	if err != nil {
+		return err
+	}
+	trackedPaths := stageCommit.GetFilesUnder(source)
+	if len(trackedPaths) == 0 {
+		return errors.New("source is not tracked: " + source)
+	}
+	if _, err := os.Stat(source); err != nil {
+		return errors.New("source does not exist in working directory: " + source)
+	}
+	if destination == source || strings.HasPrefix(destination, source+string(filepath.Separator)) {
+		return errors.New("can not move " + source + " into itself")
+	}
+	if _, err := os.Stat(destination); err == nil && !force {
+		return errors.New("destination already exists: " + destination)
+	}
+
+	if force {
+		err = os.RemoveAll(destination)
+		if err != nil {
+			return fmt.Errorf("failed to remove destination %s: %w", destination, err)
+		}
+	}
+	err = cs.repo.Rename(source, destination)
+	if err != nil {
+		return fmt.Errorf("failed to move %s to %s: %w", source, destination, err)
+	}
+	err = cs.repo.PruneEmptyDirs(filepath.Dir(source))
+	if err != nil {
+		return fmt.Errorf("failed to remove empty directories of %s: %w", source, err)
+	}
+
+	for _, path := range trackedPaths {
+		file := stageCommit.GetFile(path)
+		stageCommit.RemoveFile(path)
+		file.Path = destination + strings.TrimPrefix(path, source)
+		stageCommit.SetFile(file)
+	}
+	for _, path := range stageCommit.GetFilesUnder(destination) {
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			stageCommit.RemoveFile(path)
+		}
+	}
+
+	return cs.saveStage(stageCommit)
+}
+
+// loadStage returns the staged commit, when nothing is staged yet a new one is started
+// from the last commit on current branch.
+func (cs commitService) loadStage() (Commit, error) {
+	stagedCommit, err := cs.GetStagedCommit()
+	if err == nil {
+		return stagedCommit, nil
+	}
+
+	head, err := cs.readHead()
+	if err != nil {
+		return Commit{}, err
+	}
+	lastCommit, err := cs.GetLastCommitOnCurrentBranch()
+	if errors.Is(err, errEmptyBranch) {
+		return Commit{PreviousCommit: "nil", Files: make([]File, 0)}, nil
+	}
+	if err != nil {
+		return Commit{}, err
+	}
+
+	return Commit{PreviousCommit: head.Hash, Files: slices.Clone(lastCommit.Files)}, nil
+}
+
+// saveStage saves given commit to staging area. when the staged tree is the same as the
+// last commit there is nothing to commit, so staging area is cleared instead unless a
+// merge is being concluded.
+func (cs commitService) saveStage(stageCommit Commit) error {
+	lastFiles, err := cs.lastCommitFiles()
+	if err != nil {
+		return err
+	}
+	if !cs.isMerging() && maps.Equal(lastFiles, stageCommit.GetAllFilePaths()) {
+		return cs.clearStage()
+	}
+
+	err = cs.repo.CompressAndSaveToFile(stageCommit, filepath.Join(util.BaseFilePath, util.StageFolder, "commit"))
+	if err != nil {
+		return fmt.Errorf("failed to save commit to stage: %w", err)
+	}
+
+	return nil
+}
+
+func (cs commitService) lastCommitFiles() (map[string]string, error) {
+	lastCommit, err := cs.GetLastCommitOnCurrentBranch()
+	if errors.Is(err, errEmptyBranch) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return lastCommit.GetAllFilePaths(), nil
+}
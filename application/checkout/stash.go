@@ -0,0 +1,286 @@
+package checkout
+
+import (
+	"errors"
+	"fmt"
+	"git-light/util"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var stashEntryPattern = regexp.MustCompile(`^(?:stash@\{(\d+)\}|(\d+))$`)
+
+// StashPush saves staged and working directory changes of tracked files as a stash entry
+// and resets staging area and working directory to HEAD. an entry is a commit of working
+// directory whose parents are HEAD and a commit of staging area.
+func (cs commitService) StashPush(message string, committer string) error {
+	head, err := cs.readHead()
+	if err != nil {
+		return err
+	}
+	if cs.isMerging() {
+		return errors.New("a merge is in progress. commit the result or abort it before stashing")
+	}
+	if head.Hash == "nil" {
+		return errors.New("can not stash before the first commit")
+	}
+
+	headCommit, err := cs.findCommit(head.Hash)
+	if err != nil {
+		return err
+	}
+	staged, err := cs.GetStagedCommit()
+	if err != nil {
+		staged = headCommit
+	}
+
+	working := Commit{Files: make([]File, 0, len(staged.Files))}
+	for _, file := range staged.Files {
+		content, err := cs.readWorkingFile(file.Path)
+		if err != nil {
+			continue
+		}
+		matches, err := cs.blobMatches(file.Hash, content)
+		if err != nil {
+			return err
+		}
+		if !matches {
+			file, err = cs.stageFileContent(file.Path, content, file.Hash)
+			if err != nil {
+				return err
+			}
+		}
+		working.SetFile(file)
+	}
+
+	headFiles := headCommit.GetAllFilePaths()
+	if maps.Equal(headFiles, staged.GetAllFilePaths()) && maps.Equal(headFiles, working.GetAllFilePaths()) {
+		fmt.Println("No local changes to save")
+		return nil
+	}
+
+	branchName := head.Branch
+	if head.IsDetached() {
+		branchName = "(no branch)"
+	}
+	subject, _, _ := strings.Cut(headCommit.Message, "\n")
+	if message == "" {
+		message = "WIP on " + branchName + ": " + shortHash(head.Hash) + " " + subject
+	} else {
+		message = "On " + branchName + ": " + message
+	}
+
+	indexCommit := Commit{Files: staged.Files, Message: "index on " + branchName + ": " + shortHash(head.Hash) + " " + subject, Parents: []string{head.Hash}}
+	indexHash, err := cs.saveStashCommit(indexCommit, committer)
+	if err != nil {
+		return err
+	}
+	working.Parents = []string{head.Hash, indexHash}
+	working.Message = message
+	workingHash, err := cs.saveStashCommit(working, committer)
+	if err != nil {
+		return err
+	}
+
+	err = cs.repo.DeleteFiles(filepath.Join(util.BaseFilePath, util.StageFolder, "commit"))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to clear staging area: %w", err)
+	}
+	err = cs.repo.MoveFiles(filepath.Join(util.BaseFilePath, util.StageFolder), filepath.Join(util.BaseFilePath, util.ObjectFolder))
+	if err != nil {
+		return fmt.Errorf("failed to move stashed files to object store: %w", err)
+	}
+	err = cs.refs.PushStash(workingHash, message)
+	if err != nil {
+		return fmt.Errorf("failed to save stash entry: %w", err)
+	}
+
+	trackedFiles := working.GetAllFilePaths()
+	maps.Copy(trackedFiles, staged.GetAllFilePaths())
+	err = cs.checkoutTree(trackedFiles, headFiles, true)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Saved working directory and index state " + message)
+
+	return nil
+}
+
+// StashList prints stash entries newest first.
+func (cs commitService) StashList() error {
+	if _, err := cs.readHead(); err != nil {
+		return err
+	}
+	entries, err := cs.refs.ReadReflog(util.Stash)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read stash entries: %w", err)
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		fmt.Printf("stash@{%d}: %s\n", len(entries)-1-i, entries[i].Reason)
+	}
+
+	return nil
+}
+
+// StashApply merges changes of given stash entry, or the newest one, into working
+// directory. changes which were staged are staged again unless HEAD changed the same
+// files. conflicting hunks are written with conflict markers.
+func (cs commitService) StashApply(entry string) error {
+	return cs.applyStash(entry)
+}
+
+// StashPop applies given stash entry, or the newest one, and drops it unless it conflicted.
+func (cs commitService) StashPop(entry string) error {
+	err := cs.applyStash(entry)
+	if err != nil {
+		return err
+	}
+
+	return cs.StashDrop(entry)
+}
+
+// applyStash applies given stash entry, a util.ConflictError is returned when it conflicted.
+func (cs commitService) applyStash(entry string) error {
+	err := cs.requireCleanTree("applying stash")
+	if err != nil {
+		return err
+	}
+
+	n, err := parseStashEntry(entry)
+	if err != nil {
+		return err
+	}
+	stashHash, err := cs.revisions.Resolve(fmt.Sprintf("%s@{%d}", util.Stash, n))
+	if err != nil {
+		return err
+	}
+	working, err := cs.findCommit(stashHash)
+	if err != nil {
+		return err
+	}
+	parents := working.GetParents()
+	if len(parents) != 2 {
+		return fmt.Errorf("stash@{%d} is not a stash entry", n)
+	}
+	base, err := cs.findCommit(parents[0])
+	if err != nil {
+		return err
+	}
+	index, err := cs.findCommit(parents[1])
+	if err != nil {
+		return err
+	}
+
+	head, err := cs.readHead()
+	if err != nil {
+		return err
+	}
+	ours, err := cs.findCommit(head.Hash)
+	if err != nil {
+		return err
+	}
+	err = cs.requireNoUntrackedOverwrite(base, ours, working, "stash apply")
+	if err != nil {
+		return err
+	}
+	merged, conflicts, err := cs.mergeTrees(base, ours, working, fmt.Sprintf("stash@{%d}", n))
+	if err != nil {
+		return err
+	}
+
+	stageCommit := Commit{PreviousCommit: head.Hash, Files: slices.Clone(ours.Files)}
+	if len(conflicts) > 0 {
+		stageCommit = merged
+		stageCommit.PreviousCommit = head.Hash
+	} else {
+		baseFiles := base.GetAllFilePaths()
+		oursFiles := ours.GetAllFilePaths()
+		indexFiles := index.GetAllFilePaths()
+		for _, file := range merged.Files {
+			if _, ok := oursFiles[file.Path]; !ok {
+				stageCommit.SetFile(file)
+			}
+		}
+		for path, hash := range indexFiles {
+			if baseHash, ok := baseFiles[path]; (!ok || baseHash != hash) && oursFiles[path] == baseFiles[path] {
+				stageCommit.SetFile(index.GetFile(path))
+			}
+		}
+		for path := range baseFiles {
+			if _, ok := indexFiles[path]; !ok && oursFiles[path] == baseFiles[path] {
+				stageCommit.RemoveFile(path)
+			}
+		}
+	}
+	err = cs.saveStage(stageCommit)
+	if err != nil {
+		return err
+	}
+
+	if len(conflicts) > 0 {
+		return util.ConflictError{Message: "fix conflicts and add them, the stash entry is kept in case you need it again", Paths: conflicts}
+	}
+
+	return nil
+}
+
+// StashDrop removes given stash entry, or the newest one.
+func (cs commitService) StashDrop(entry string) error {
+	n, err := parseStashEntry(entry)
+	if err != nil {
+		return err
+	}
+	stashHash, err := cs.revisions.Resolve(fmt.Sprintf("%s@{%d}", util.Stash, n))
+	if err != nil {
+		return err
+	}
+
+	err = cs.refs.DropStash(n)
+	if err != nil {
+		return fmt.Errorf("failed to drop stash entry: %w", err)
+	}
+	fmt.Printf("Dropped stash@{%d} (%s)\n", n, stashHash)
+
+	return nil
+}
+
+// saveStashCommit writes given stash commit to the object store and returns its hash.
+func (cs commitService) saveStashCommit(commit Commit, committer string) (string, error) {
+	commit.Version = CurrentCommitVersion
+	commit.Committer = committer
+	commit.Date = time.Now()
+	commit.PreviousCommit = commit.Parents[0]
+
+	commitHash := commit.CalculateHashForCommit()
+	err := cs.repo.CompressAndSaveToFile(commit, filepath.Join(util.BaseFilePath, util.ObjectFolder, commitHash))
+	if err != nil {
+		return "", fmt.Errorf("failed to save stash commit: %w", err)
+	}
+
+	return commitHash, nil
+}
+
+// parseStashEntry accepts stash@{n} or n, empty entry is the newest one.
+func parseStashEntry(entry string) (int, error) {
+	if entry == "" {
+		return 0, nil
+	}
+
+	matches := stashEntryPattern.FindStringSubmatch(entry)
+	if matches == nil {
+		return 0, errors.New("invalid stash entry: " + entry)
+	}
+	n, err := strconv.Atoi(matches[1] + matches[2])
+	if err != nil {
+		return 0, errors.New("invalid stash entry: " + entry)
+	}
+
+	return n, nil
+}
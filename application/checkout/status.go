@@ -0,0 +1,208 @@
+package checkout
+
+import (
+	"errors"
+	"fmt"
+	"git-light/util"
+	"os"
+	"slices"
+)
+
+type StatusCode uint
+
+const (
+	Untracked StatusCode = iota
+	Modified
+	Deleted
+	StagedNew
+	StagedModified
+	StagedDeleted
+	StagedRenamed
+)
+
+type FileStatus struct {
+	Path    string
+	OldPath string
+	Code    StatusCode
+	Binary  bool
+}
+
+func (sc StatusCode) IsStaged() bool {
+	return sc == StagedNew || sc == StagedModified || sc == StagedDeleted || sc == StagedRenamed
+}
+
+func (sc StatusCode) String() string {
+	switch sc {
+	case Untracked:
+		return "untracked"
+	case Modified, StagedModified:
+		return "modified"
+	case Deleted, StagedDeleted:
+		return "deleted"
+	case StagedNew:
+		return "new file"
+	case StagedRenamed:
+		return "renamed"
+	}
+	return "unknown"
+}
+
+func (cs commitService) Status() ([]FileStatus, error) {
+	head, err := cs.readHead()
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := cs.collectStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	if head.IsDetached() {
+		fmt.Printf("HEAD detached at %s\n", head.Hash)
+	} else {
+		fmt.Printf("On branch %s\n", head.Branch)
+	}
+	if cs.isMerging() {
+		fmt.Println("You have unmerged paths, fix conflicts and commit the result.")
+	}
+	for _, op := range pickOperations {
+		if op.inProgress() {
+			fmt.Printf("You are in the middle of a %s, fix conflicts and run '%s --continue' or '%s --abort'.\n", op.name, op.name, op.name)
+		}
+	}
+	if len(statuses) == 0 {
+		fmt.Println("nothing to commit, working tree clean")
+		return statuses, nil
+	}
+
+	color := util.IsTerminal(os.Stdout)
+	cs.printStatusSection("Changes to be committed:", "\033[32m", color, statuses, func(fs FileStatus) bool {
+		return fs.Code.IsStaged()
+	})
+	cs.printStatusSection("Changes not staged for commit:", "\033[31m", color, statuses, func(fs FileStatus) bool {
+		return fs.Code == Modified || fs.Code == Deleted
+	})
+	cs.printStatusSection("Untracked files:", "\033[31m", color, statuses, func(fs FileStatus) bool {
+		return fs.Code == Untracked
+	})
+
+	return statuses, nil
+}
+
+// collectStatus compares HEAD with the staged commit and the staged commit with the
+// working directory. when nothing is staged, the staged tree is the same as HEAD. staged
+// deletions and additions of similar files are reported as renames.
+func (cs commitService) collectStatus() ([]FileStatus, error) {
+	lastCommit, err := cs.GetLastCommitOnCurrentBranch()
+	if err != nil && !errors.Is(err, errEmptyBranch) {
+		return nil, err
+	}
+	headFiles := lastCommit.GetAllFilePaths()
+
+	stagedCommit, err := cs.GetStagedCommit()
+	if err != nil {
+		stagedCommit = lastCommit
+	}
+	stageFiles := stagedCommit.GetAllFilePaths()
+
+	workingFiles, err := cs.listWorkingFiles(".", stagedCommit)
+	if err != nil {
+		return nil, err
+	}
+
+	renames, err := cs.detectRenames(cs.commitSide(lastCommit), cs.commitSide(stagedCommit))
+	if err != nil {
+		return nil, err
+	}
+	renamedFrom := make(map[string]string)
+	renamedTo := make(map[string]bool)
+	for _, r := range renames {
+		renamedFrom[r.To] = r.From
+		renamedTo[r.From] = true
+	}
+
+	statuses := make([]FileStatus, 0)
+	for _, path := range sortedPaths(stageFiles) {
+		headHash, ok := headFiles[path]
+		binary := stagedCommit.GetFile(path).Binary
+		if oldPath, renamed := renamedFrom[path]; renamed {
+			statuses = append(statuses, FileStatus{Path: path, OldPath: oldPath, Code: StagedRenamed, Binary: binary})
+		} else if !ok {
+			statuses = append(statuses, FileStatus{Path: path, Code: StagedNew, Binary: binary})
+		} else if headHash != stageFiles[path] {
+			statuses = append(statuses, FileStatus{Path: path, Code: StagedModified, Binary: binary})
+		}
+	}
+
+	for _, path := range sortedPaths(headFiles) {
+		if _, ok := stageFiles[path]; !ok && !renamedTo[path] {
+			statuses = append(statuses, FileStatus{Path: path, Code: StagedDeleted, Binary: lastCommit.GetFile(path).Binary})
+		}
+	}
+
+	for _, path := range sortedPaths(stageFiles) {
+		content, err := cs.readWorkingFile(path)
+		if err != nil {
+			statuses = append(statuses, FileStatus{Path: path, Code: Deleted, Binary: stagedCommit.GetFile(path).Binary})
+			continue
+		}
+		matches, err := cs.blobMatches(stageFiles[path], content)
+		if err != nil {
+			return nil, err
+		}
+		if !matches {
+			statuses = append(statuses, FileStatus{Path: path, Code: Modified, Binary: isBinary(content)})
+		}
+	}
+
+	slices.Sort(workingFiles)
+	for _, path := range workingFiles {
+		if _, ok := stageFiles[path]; ok {
+			continue
+		}
+		content, err := cs.readWorkingFile(path)
+		statuses = append(statuses, FileStatus{Path: path, Code: Untracked, Binary: err == nil && isBinary(content)})
+	}
+
+	return statuses, nil
+}
+
+func (cs commitService) printStatusSection(title string, code string, color bool, statuses []FileStatus, filter func(FileStatus) bool) {
+	var printed = false
+	for _, fs := range statuses {
+		if !filter(fs) {
+			continue
+		}
+		if !printed {
+			fmt.Println(title)
+			printed = true
+		}
+		var binaryMark string
+		if fs.Binary {
+			binaryMark = " (binary)"
+		}
+		path := fs.Path
+		if fs.OldPath != "" {
+			path = fs.OldPath + " -> " + fs.Path
+		}
+		if fs.Code == Untracked {
+			fmt.Println("\t" + colorize(color, code, path+binaryMark))
+		} else {
+			fmt.Println("\t" + colorize(color, code, fmt.Sprintf("%-12s%s%s", fs.Code.String()+":", path, binaryMark)))
+		}
+	}
+
+	if printed {
+		fmt.Println()
+	}
+}
+
+func sortedPaths(files map[string]string) []string {
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	return paths
+}
@@ -0,0 +1,267 @@
+package checkout
+
+import (
+	"fmt"
+	"git-light/util"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// checkoutConflicts returns paths whose staged or working directory changes would be lost
+// by moving from current tree to target tree. paths which are the same in both trees keep
+// their changes, so they never conflict.
+func (cs commitService) checkoutConflicts(current, staged, target map[string]string) ([]string, error) {
+	paths := sortedPaths(current)
+	for _, files := range []map[string]string{staged, target} {
+		for path := range files {
+			if !slices.Contains(paths, path) {
+				paths = append(paths, path)
+			}
+		}
+	}
+	slices.Sort(paths)
+
+	conflicts := make([]string, 0)
+	for _, path := range paths {
+		currentHash, inCurrent := current[path]
+		stagedHash, inStaged := staged[path]
+		targetHash, inTarget := target[path]
+		if inCurrent == inTarget && currentHash == targetHash {
+			continue
+		}
+
+		stagedChanged := inStaged != inCurrent || stagedHash != currentHash
+		stagedIsTarget := inStaged == inTarget && stagedHash == targetHash
+		if stagedChanged && !stagedIsTarget {
+			conflicts = append(conflicts, path)
+			continue
+		}
+
+		content, err := cs.readWorkingFile(path)
+		if err != nil {
+			continue
+		}
+		if inTarget {
+			matches, err := cs.blobMatches(targetHash, content)
+			if err != nil {
+				return nil, err
+			}
+			if matches {
+				continue
+			}
+		}
+		if !inStaged {
+			if inTarget {
+				conflicts = append(conflicts, path)
+			}
+			continue
+		}
+		matches, err := cs.blobMatches(stagedHash, content)
+		if err != nil {
+			return nil, err
+		}
+		if !matches {
+			conflicts = append(conflicts, path)
+		}
+	}
+
+	return conflicts, nil
+}
+
+// requireNoUntrackedOverwrite stops given operation when it would overwrite untracked
+// working files, those are paths missing in ours which theirs changed since base.
+func (cs commitService) requireNoUntrackedOverwrite(base, ours, theirs Commit, operation string) error {
+	baseFiles := base.GetAllFilePaths()
+	oursFiles := ours.GetAllFilePaths()
+	theirsFiles := theirs.GetAllFilePaths()
+
+	conflicts := make([]string, 0)
+	for _, path := range sortedPaths(theirsFiles) {
+		hash := theirsFiles[path]
+		if _, ok := oursFiles[path]; ok {
+			continue
+		}
+		if baseHash, ok := baseFiles[path]; ok && baseHash == hash {
+			continue
+		}
+
+		content, err := cs.readWorkingFile(path)
+		if err != nil {
+			continue
+		}
+		matches, err := cs.blobMatches(hash, content)
+		if err != nil {
+			return err
+		}
+		if !matches {
+			conflicts = append(conflicts, path)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return util.ConflictError{Message: "the following untracked working tree files would be overwritten by " + operation + ". move or remove them first", Paths: conflicts}
+	}
+
+	return nil
+}
+
+// checkoutTree writes files of target tree which differ from given tree and removes the
+// ones missing in target tree. with force, every target file which differs from working
+// directory is written. contents are read before working directory is touched, so a
+// missing object doesn't leave a half checked out tree behind.
+func (cs commitService) checkoutTree(from, to map[string]string, force bool) error {
+	contents := make(map[string][]byte)
+	for path, hash := range to {
+		if from[path] == hash && !force {
+			continue
+		}
+		content, err := cs.readWorkingFile(path)
+		if err == nil {
+			matches, err := cs.blobMatches(hash, content)
+			if err != nil {
+				return err
+			}
+			if matches {
+				continue
+			}
+		}
+		contents[path], err = cs.ExtractContentFromObjectStore(hash)
+		if err != nil {
+			return err
+		}
+	}
+
+	for path := range from {
+		if _, ok := to[path]; !ok {
+			err := cs.removeWorkingFile(path)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, path := range sortedPaths(to) {
+		if content, ok := contents[path]; ok {
+			err := cs.writeWorkingFile(path, content)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// carryStagedChanges stages changes which were staged on top of current commit on top of
+// checked out commit instead, checkoutConflicts makes sure they don't touch the same paths.
+func (cs commitService) carryStagedChanges(current, staged, target Commit, targetHash string) error {
+	stageCommit := Commit{PreviousCommit: targetHash, Files: slices.Clone(target.Files)}
+	currentFiles := current.GetAllFilePaths()
+	stagedFiles := staged.GetAllFilePaths()
+
+	for path, hash := range stagedFiles {
+		if currentHash, ok := currentFiles[path]; !ok || currentHash != hash {
+			stageCommit.SetFile(staged.GetFile(path))
+		}
+	}
+	for path := range currentFiles {
+		if _, ok := stagedFiles[path]; !ok {
+			stageCommit.RemoveFile(path)
+		}
+	}
+
+	return cs.saveStage(stageCommit)
+}
+
+// writeBlob writes content of the blob with given hash to given path of working directory.
+func (cs commitService) writeBlob(path string, hash string) error {
+	content, err := cs.ExtractContentFromObjectStore(hash)
+	if err != nil {
+		return err
+	}
+
+	return cs.writeWorkingFile(path, content)
+}
+
+func (cs commitService) writeWorkingFile(path string, content []byte) error {
+	err := cs.repo.WriteFile(path, cs.attributes.Smudge(path, content))
+	if err != nil {
+		return fmt.Errorf("failed to write file to working directory %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// removeWorkingFile deletes a file from working directory together with directories
+// left empty after it.
+func (cs commitService) removeWorkingFile(path string) error {
+	err := cs.repo.DeleteFiles(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove file from working directory %s: %w", path, err)
+	}
+
+	err = cs.repo.PruneEmptyDirs(filepath.Dir(path))
+	if err != nil {
+		return fmt.Errorf("failed to remove empty directories of %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// expandPaths cleans given paths and replaces directories with all files under them which
+// are not ignored. ignore rules only apply to paths which aren't tracked in given tree,
+// ignored paths given explicitly are skipped with a warning.
+func (cs commitService) expandPaths(paths []string, tracked Commit) ([]string, error) {
+	isIgnored := cs.ignoreUntracked(tracked)
+	expanded := make([]string, 0, len(paths))
+	for _, path := range paths {
+		path = filepath.Clean(path)
+		info, err := os.Stat(path)
+		isDir := err == nil && info.IsDir()
+
+		if isIgnored(path, isDir) {
+			log.Println("path is ignored by " + util.IgnoreFile + ", skipping: " + path)
+			continue
+		}
+
+		files := []string{path}
+		if isDir {
+			files, err = cs.listWorkingFiles(path, tracked)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, file := range files {
+			if !slices.Contains(expanded, file) {
+				expanded = append(expanded, file)
+			}
+		}
+	}
+
+	return expanded, nil
+}
+
+// listWorkingFiles lists files under given directory of working directory, ignored files
+// and directories are left out unless they are tracked in given tree.
+func (cs commitService) listWorkingFiles(root string, tracked Commit) ([]string, error) {
+	files, err := cs.repo.ListFiles(root, cs.ignoreUntracked(tracked))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list files in working directory: %w", err)
+	}
+
+	return files, nil
+}
+
+// ignoreUntracked returns a function reporting whether a path is ignored, tracked files
+// and directories holding them never are.
+func (cs commitService) ignoreUntracked(tracked Commit) func(path string, isDir bool) bool {
+	return func(path string, isDir bool) bool {
+		if len(tracked.GetFilesUnder(path)) > 0 {
+			return false
+		}
+
+		return cs.ignore.IsIgnored(path, isDir)
+	}
+}
@@ -0,0 +1,41 @@
+package checkout
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestExpandPathsIgnoresUntrackedOnly(t *testing.T) {
+	cs := setupCommitService(t)
+
+	files := []string{"tracked.log", "new.log", filepath.Join("build", "keep.txt"), filepath.Join("build", "new.txt"), "main.go"}
+	for _, path := range files {
+		writeWorkingFile(t, path, "content\n")
+	}
+	writeWorkingFile(t, ".gitlightignore", "*.log\nbuild/\n")
+	tracked := Commit{Files: []File{{Path: "tracked.log"}, {Path: filepath.Join("build", "keep.txt")}}}
+
+	tests := []struct {
+		name  string
+		paths []string
+		want  []string
+	}{
+		{name: "directory", paths: []string{"."}, want: []string{".gitlightignore", filepath.Join("build", "keep.txt"), "main.go", "tracked.log"}},
+		{name: "ignored directory holding tracked files", paths: []string{"build"}, want: []string{filepath.Join("build", "keep.txt")}},
+		{name: "explicit files", paths: []string{"tracked.log", "new.log"}, want: []string{"tracked.log"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cs.expandPaths(tt.paths, tracked)
+			if err != nil {
+				t.Fatalf("expandPaths(%q) returned error: %v", tt.paths, err)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("expandPaths(%q) = %q, want %q", tt.paths, got, tt.want)
+			}
+		})
+	}
+}
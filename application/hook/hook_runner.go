@@ -0,0 +1,50 @@
+package hook
+
+import (
+	"git-light/util"
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+const (
+	PreCommit    = "pre-commit"
+	CommitMsg    = "commit-msg"
+	PostCommit   = "post-commit"
+	PostCheckout = "post-checkout"
+
+	CommitEnv = "GIT_LIGHT_COMMIT"
+	BranchEnv = "GIT_LIGHT_BRANCH"
+)
+
+type HookRunner interface {
+	Run(name string, args []string, env []string) error
+}
+
+type hookRunner struct {
+	hooksDir string
+}
+
+func NewHookRunner() HookRunner {
+	return hookRunner{
+		hooksDir: filepath.Join(util.BaseFilePath, util.HookFolder),
+	}
+}
+
+// Run executes hook with given name if it exists and is executable, missing hooks are
+// not an error. non-zero exit status of the hook is returned as an error.
+func (hr hookRunner) Run(name string, args []string, env []string) error {
+	path := filepath.Join(hr.hooksDir, name)
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+		return nil
+	}
+
+	command := exec.Command(path, args...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+	command.Env = append(os.Environ(), env...)
+
+	return command.Run()
+}
@@ -0,0 +1,206 @@
+package ignore
+
+import (
+	"git-light/application/repository"
+	"git-light/util"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+type IgnoreMatcher interface {
+	IsIgnored(p string, isDir bool) bool
+}
+
+type pattern struct {
+	regex   *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+type ignoreMatcher struct {
+	repo     repository.Repository
+	global   []pattern
+	patterns map[string][]pattern
+}
+
+// NewIgnoreMatcher returns a matcher for working directory, global excludes file is read
+// from GIT_LIGHT_EXCLUDES_FILE or from git-light/ignore under user config directory.
+func NewIgnoreMatcher(repo repository.Repository) IgnoreMatcher {
+	im := ignoreMatcher{
+		repo:     repo,
+		global:   make([]pattern, 0),
+		patterns: make(map[string][]pattern),
+	}
+
+	excludesFile := os.Getenv(util.ExcludesFileEnv)
+	if configDir, err := os.UserConfigDir(); excludesFile == "" && err == nil {
+		excludesFile = filepath.Join(configDir, "git-light", "ignore")
+	}
+	if lines, err := repo.GetFileLines(excludesFile); excludesFile != "" && err == nil {
+		im.global = parsePatterns(lines, "")
+	}
+
+	return im
+}
+
+// IsIgnored reports whether given path relative to repository root is ignored. patterns of
+// global excludes file, root ignore file and ignore files of nested directories are checked
+// in this order and the last matching pattern decides. a path inside an ignored directory
+// is always ignored like git does.
+func (im ignoreMatcher) IsIgnored(p string, isDir bool) bool {
+	p = strings.TrimPrefix(path.Clean("/"+filepath.ToSlash(p)), "/")
+	if p == "" {
+		return false
+	}
+
+	parts := strings.Split(p, "/")
+	if parts[0] == util.BaseFilePath || parts[0] == ".git" {
+		return true
+	}
+
+	for i := 1; i < len(parts); i++ {
+		if im.matches(strings.Join(parts[:i], "/"), true) {
+			return true
+		}
+	}
+
+	return im.matches(p, isDir)
+}
+
+func (im ignoreMatcher) matches(p string, isDir bool) bool {
+	ignored := false
+	check := func(patterns []pattern) {
+		for _, pt := range patterns {
+			if (!pt.dirOnly || isDir) && pt.regex.MatchString(p) {
+				ignored = !pt.negate
+			}
+		}
+	}
+
+	check(im.global)
+	dir := ""
+	check(im.patternsOf(dir))
+	for _, part := range strings.Split(path.Dir(p), "/") {
+		if part == "." {
+			break
+		}
+		dir = path.Join(dir, part)
+		check(im.patternsOf(dir))
+	}
+
+	return ignored
+}
+
+// patternsOf reads and caches patterns of the ignore file placed in given directory.
+func (im ignoreMatcher) patternsOf(dir string) []pattern {
+	if patterns, ok := im.patterns[dir]; ok {
+		return patterns
+	}
+
+	patterns := make([]pattern, 0)
+	if lines, err := im.repo.GetFileLines(filepath.Join(filepath.FromSlash(dir), util.IgnoreFile)); err == nil {
+		patterns = parsePatterns(lines, dir)
+	}
+	im.patterns[dir] = patterns
+
+	return patterns
+}
+
+func parsePatterns(lines []string, base string) []pattern {
+	patterns := make([]pattern, 0)
+	for _, line := range lines {
+		line = trimTrailingSpaces(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var pt pattern
+		if strings.HasPrefix(line, "!") {
+			pt.negate = true
+			line = line[1:]
+		} else if strings.HasPrefix(line, "\\!") || strings.HasPrefix(line, "\\#") {
+			line = line[1:]
+		}
+
+		if strings.HasSuffix(line, "/") {
+			pt.dirOnly = true
+			line = strings.TrimSuffix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		anchored := strings.Contains(line, "/")
+		line = strings.TrimPrefix(line, "/")
+
+		expression := "^"
+		if base != "" {
+			expression += regexp.QuoteMeta(base + "/")
+		}
+		if !anchored {
+			expression += "(?:.*/)?"
+		}
+		expression += globToRegex(line) + "$"
+
+		regex, err := regexp.Compile(expression)
+		if err != nil {
+			continue
+		}
+		pt.regex = regex
+		patterns = append(patterns, pt)
+	}
+
+	return patterns
+}
+
+// globToRegex converts a gitignore glob to a regular expression. * and ? never match a
+// slash, leading **/ matches any directory, trailing /** matches everything inside and
+// /**/ matches zero or more directories.
+func globToRegex(glob string) string {
+	var expression strings.Builder
+	for i := 0; i < len(glob); i++ {
+		switch c := glob[i]; {
+		case strings.HasPrefix(glob[i:], "**/") && (i == 0 || glob[i-1] == '/'):
+			expression.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			expression.WriteString(".*")
+			i++
+		case c == '*':
+			expression.WriteString("[^/]*")
+		case c == '?':
+			expression.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end == -1 {
+				expression.WriteString(regexp.QuoteMeta("["))
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expression.WriteString("[" + strings.ReplaceAll(class, "\\", "\\\\") + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			expression.WriteString(regexp.QuoteMeta(string(glob[i+1])))
+			i++
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return expression.String()
+}
+
+func trimTrailingSpaces(line string) string {
+	trimmed := strings.TrimRight(line, " ")
+	if strings.HasSuffix(trimmed, "\\") && len(trimmed) < len(line) {
+		return trimmed + " "
+	}
+
+	return trimmed
+}
@@ -0,0 +1,50 @@
+package ignore
+
+import (
+	"git-light/application/repository"
+	"testing"
+)
+
+func TestIsIgnored(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		path     string
+		isDir    bool
+		want     bool
+	}{
+		{name: "star matches in any directory", patterns: []string{"*.log"}, path: "src/debug.log", want: true},
+		{name: "star doesn't match other extensions", patterns: []string{"*.log"}, path: "src/debug.txt", want: false},
+		{name: "star doesn't cross slashes", patterns: []string{"src/*.go"}, path: "src/pkg/main.go", want: false},
+		{name: "question mark matches one character", patterns: []string{"file?.txt"}, path: "file1.txt", want: true},
+		{name: "question mark needs a character", patterns: []string{"file?.txt"}, path: "file.txt", want: false},
+		{name: "character class", patterns: []string{"file[0-9].txt"}, path: "file7.txt", want: true},
+		{name: "negated character class", patterns: []string{"file[!0-9].txt"}, path: "file7.txt", want: false},
+		{name: "leading slash anchors to root", patterns: []string{"/build"}, path: "src/build", want: false},
+		{name: "anchored pattern matches at root", patterns: []string{"/build"}, path: "build", isDir: true, want: true},
+		{name: "trailing slash matches directories only", patterns: []string{"build/"}, path: "build", want: false},
+		{name: "files inside ignored directory", patterns: []string{"node_modules/"}, path: "web/node_modules/lib/index.js", want: true},
+		{name: "leading double star", patterns: []string{"**/cache"}, path: "a/b/cache", want: true},
+		{name: "trailing double star", patterns: []string{"logs/**"}, path: "logs/2024/app.txt", want: true},
+		{name: "double star between directories", patterns: []string{"a/**/z.txt"}, path: "a/z.txt", want: true},
+		{name: "double star spans directories", patterns: []string{"a/**/z.txt"}, path: "a/b/c/z.txt", want: true},
+		{name: "negation re-includes", patterns: []string{"*.log", "!keep.log"}, path: "keep.log", want: false},
+		{name: "later pattern wins", patterns: []string{"!keep.log", "*.log"}, path: "keep.log", want: true},
+		{name: "comments are skipped", patterns: []string{"# *.log"}, path: "debug.log", want: false},
+		{name: "escaped hash", patterns: []string{"\\#notes"}, path: "#notes", want: true},
+		{name: "repository folder is always ignored", patterns: []string{}, path: ".git-light/HEAD", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			im := ignoreMatcher{
+				repo:     repository.NewRepository(),
+				global:   parsePatterns(tt.patterns, ""),
+				patterns: make(map[string][]pattern),
+			}
+			if got := im.IsIgnored(tt.path, tt.isDir); got != tt.want {
+				t.Errorf("IsIgnored(%q) with %q = %v, want %v", tt.path, tt.patterns, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,13 @@
 package myersdiff
 
+// Diff is a blob saved in object store, either the whole file or a delta against the
+// blob named by PreviousBlobHash. Exact blobs keep line terminators in Data, blobs saved
+// by older versions have lines without them.
 type Diff struct {
 	PreviousBlobHash string
 	Commands         string
 	Data             []string
+	Binary           bool
+	Content          []byte
+	Exact            bool
 }
@@ -0,0 +1,22 @@
+package myersdiff
+
+// MatchLines maps every line of dst to the line of src it is kept from by the shortest
+// edit script, lines inserted in dst are mapped to -1.
+func (myers myers) MatchLines(src, dst []string) []int {
+	matches := make([]int, 0, len(dst))
+	srcIndex := 0
+
+	for _, op := range myers.shortestEditScript(src, dst) {
+		switch op {
+		case INSERT:
+			matches = append(matches, -1)
+		case MOVE:
+			matches = append(matches, srcIndex)
+			srcIndex += 1
+		case DELETE:
+			srcIndex += 1
+		}
+	}
+
+	return matches
+}
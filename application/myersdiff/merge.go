@@ -0,0 +1,128 @@
+package myersdiff
+
+import (
+	"slices"
+	"strings"
+)
+
+// chunk is a changed region of base, lines between start and end are replaced by lines.
+type chunk struct {
+	start int
+	end   int
+	lines []string
+}
+
+// Merge applies changes made from base to ours and from base to theirs at the same time.
+// changes touching the same region of base are written between conflict markers unless
+// both sides made the same change, second return value reports whether any conflict exists.
+// lines are expected to keep their terminators.
+func (myers myers) Merge(base, ours, theirs []string, oursLabel, theirsLabel string) ([]string, bool) {
+	oursChunks := myers.chunksOf(base, ours)
+	theirsChunks := myers.chunksOf(base, theirs)
+
+	result := make([]string, 0, len(base))
+	hasConflict := false
+	baseIndex, oursIndex, theirsIndex := 0, 0, 0
+
+	for oursIndex < len(oursChunks) || theirsIndex < len(theirsChunks) {
+		start := -1
+		if oursIndex < len(oursChunks) {
+			start = oursChunks[oursIndex].start
+		}
+		if theirsIndex < len(theirsChunks) && (start == -1 || theirsChunks[theirsIndex].start < start) {
+			start = theirsChunks[theirsIndex].start
+		}
+
+		end := start
+		oursGroup, theirsGroup := make([]chunk, 0), make([]chunk, 0)
+		for {
+			if oursIndex < len(oursChunks) && oursChunks[oursIndex].start <= end {
+				end = max(end, oursChunks[oursIndex].end)
+				oursGroup = append(oursGroup, oursChunks[oursIndex])
+				oursIndex++
+			} else if theirsIndex < len(theirsChunks) && theirsChunks[theirsIndex].start <= end {
+				end = max(end, theirsChunks[theirsIndex].end)
+				theirsGroup = append(theirsGroup, theirsChunks[theirsIndex])
+				theirsIndex++
+			} else {
+				break
+			}
+		}
+
+		result = append(result, base[baseIndex:start]...)
+		baseIndex = end
+
+		oursLines := applyChunks(base, start, end, oursGroup)
+		theirsLines := applyChunks(base, start, end, theirsGroup)
+		switch {
+		case len(theirsGroup) == 0:
+			result = append(result, oursLines...)
+		case len(oursGroup) == 0:
+			result = append(result, theirsLines...)
+		case slices.Equal(oursLines, theirsLines):
+			result = append(result, oursLines...)
+		default:
+			hasConflict = true
+			result = appendMarker(result, "<<<<<<< "+oursLabel)
+			result = append(result, oursLines...)
+			result = appendMarker(result, "=======")
+			result = append(result, theirsLines...)
+			result = appendMarker(result, ">>>>>>> "+theirsLabel)
+		}
+	}
+
+	result = append(result, base[baseIndex:]...)
+	return result, hasConflict
+}
+
+func (myers myers) chunksOf(src, dst []string) []chunk {
+	chunks := make([]chunk, 0)
+	var current *chunk
+
+	for _, e := range myers.editsOf(src, dst) {
+		if e.op == MOVE {
+			if current != nil {
+				chunks = append(chunks, *current)
+				current = nil
+			}
+			continue
+		}
+
+		if current == nil {
+			current = &chunk{start: e.srcIndex, end: e.srcIndex, lines: make([]string, 0)}
+		}
+		if e.op == DELETE {
+			current.end = e.srcIndex + 1
+		} else {
+			current.lines = append(current.lines, dst[e.dstIndex])
+		}
+	}
+
+	if current != nil {
+		chunks = append(chunks, *current)
+	}
+	return chunks
+}
+
+// applyChunks rewrites base lines between start and end with given chunks of one side.
+func applyChunks(base []string, start, end int, chunks []chunk) []string {
+	lines := make([]string, 0)
+	index := start
+	for _, c := range chunks {
+		lines = append(lines, base[index:c.start]...)
+		lines = append(lines, c.lines...)
+		index = c.end
+	}
+
+	return append(lines, base[index:end]...)
+}
+
+// appendMarker adds a conflict marker line, previous line gets a terminator if it is the
+// last line of a file without one so that the marker starts on its own line.
+func appendMarker(lines []string, marker string) []string {
+	if len(lines) > 0 && !strings.HasSuffix(lines[len(lines)-1], "\n") {
+		lines[len(lines)-1] += "\n"
+	}
+
+	return append(lines, marker+"\n")
+}
@@ -0,0 +1,82 @@
+package myersdiff
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name         string
+		base         []string
+		ours         []string
+		theirs       []string
+		want         []string
+		wantConflict bool
+	}{
+		{
+			name:   "only ours changed",
+			base:   []string{"a\n", "b\n", "c\n"},
+			ours:   []string{"a\n", "B\n", "c\n"},
+			theirs: []string{"a\n", "b\n", "c\n"},
+			want:   []string{"a\n", "B\n", "c\n"},
+		},
+		{
+			name:   "only theirs changed",
+			base:   []string{"a\n", "b\n", "c\n"},
+			ours:   []string{"a\n", "b\n", "c\n"},
+			theirs: []string{"a\n", "b\n", "C\n", "d\n"},
+			want:   []string{"a\n", "b\n", "C\n", "d\n"},
+		},
+		{
+			name:   "changes in separate regions",
+			base:   []string{"a\n", "b\n", "c\n", "d\n", "e\n"},
+			ours:   []string{"A\n", "b\n", "c\n", "d\n", "e\n"},
+			theirs: []string{"a\n", "b\n", "c\n", "d\n", "E\n"},
+			want:   []string{"A\n", "b\n", "c\n", "d\n", "E\n"},
+		},
+		{
+			name:   "same change on both sides",
+			base:   []string{"a\n", "b\n", "c\n"},
+			ours:   []string{"a\n", "X\n", "c\n"},
+			theirs: []string{"a\n", "X\n", "c\n"},
+			want:   []string{"a\n", "X\n", "c\n"},
+		},
+		{
+			name:   "both sides add the same file",
+			base:   nil,
+			ours:   []string{"a\n"},
+			theirs: []string{"a\n"},
+			want:   []string{"a\n"},
+		},
+		{
+			name:         "conflicting changes",
+			base:         []string{"a\n", "b\n", "c\n"},
+			ours:         []string{"a\n", "X\n", "c\n"},
+			theirs:       []string{"a\n", "Y\n", "c\n"},
+			want:         []string{"a\n", "<<<<<<< ours\n", "X\n", "=======\n", "Y\n", ">>>>>>> theirs\n", "c\n"},
+			wantConflict: true,
+		},
+		{
+			name:         "conflict on last line without terminator",
+			base:         []string{"a"},
+			ours:         []string{"b"},
+			theirs:       []string{"c"},
+			want:         []string{"<<<<<<< ours\n", "b\n", "=======\n", "c\n", ">>>>>>> theirs\n"},
+			wantConflict: true,
+		},
+	}
+
+	myers := NewMyersDiffCalculator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, conflict := myers.Merge(tt.base, tt.ours, tt.theirs, "ours", "theirs")
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Merge() = %q, want %q", got, tt.want)
+			}
+			if conflict != tt.wantConflict {
+				t.Errorf("Merge() conflict = %v, want %v", conflict, tt.wantConflict)
+			}
+		})
+	}
+}
@@ -6,6 +6,10 @@ import (
 
 type Myers interface {
 	GenerateDiffScript(src, dst []string) Diff
+	GenerateHunks(src, dst []string, context int) []Hunk
+	Merge(base, ours, theirs []string, oursLabel, theirsLabel string) ([]string, bool)
+	Similarity(src, dst []string) int
+	MatchLines(src, dst []string) []int
 }
 
 type myers struct {
@@ -0,0 +1,18 @@
+package myersdiff
+
+// Similarity returns how similar given files are as a percentage, it is the share of
+// lines kept by the shortest edit script among lines of both files.
+func (myers myers) Similarity(src, dst []string) int {
+	if len(src)+len(dst) == 0 {
+		return 100
+	}
+
+	var kept = 0
+	for _, op := range myers.shortestEditScript(src, dst) {
+		if op == MOVE {
+			kept++
+		}
+	}
+
+	return 200 * kept / (len(src) + len(dst))
+}
@@ -0,0 +1,114 @@
+package myersdiff
+
+import (
+	"strconv"
+)
+
+type Hunk struct {
+	SrcStart int
+	SrcLines int
+	DstStart int
+	DstLines int
+	Lines    []string
+}
+
+type edit struct {
+	op       operation
+	srcIndex int
+	dstIndex int
+}
+
+func (h Hunk) Header() string {
+	return "@@ -" + hunkRange(h.SrcStart, h.SrcLines) + " +" + hunkRange(h.DstStart, h.DstLines) + " @@"
+}
+
+// GenerateHunks groups the shortest edit script into unified diff hunks. changes closer
+// than twice the context size are merged into the same hunk like diff -u does. negative
+// context is treated as no context.
+func (myers myers) GenerateHunks(src, dst []string, context int) []Hunk {
+	context = max(context, 0)
+	edits := myers.editsOf(src, dst)
+	hunks := make([]Hunk, 0)
+
+	i := 0
+	for i < len(edits) {
+		if edits[i].op == MOVE {
+			i++
+			continue
+		}
+
+		start := max(i-context, 0)
+		end := i
+		for j := i; j < len(edits); j++ {
+			if edits[j].op != MOVE {
+				end = j
+			} else if j-end > 2*context {
+				break
+			}
+		}
+		stop := min(end+context+1, len(edits))
+
+		hunks = append(hunks, myers.buildHunk(src, dst, edits[start:stop]))
+		i = stop
+	}
+
+	return hunks
+}
+
+func (myers myers) editsOf(src, dst []string) []edit {
+	script := myers.shortestEditScript(src, dst)
+	edits := make([]edit, 0, len(script))
+	srcIndex, dstIndex := 0, 0
+
+	for _, op := range script {
+		edits = append(edits, edit{op: op, srcIndex: srcIndex, dstIndex: dstIndex})
+		switch op {
+		case INSERT:
+			dstIndex += 1
+		case MOVE:
+			srcIndex += 1
+			dstIndex += 1
+		case DELETE:
+			srcIndex += 1
+		}
+	}
+
+	return edits
+}
+
+func (myers myers) buildHunk(src, dst []string, edits []edit) Hunk {
+	hunk := Hunk{Lines: make([]string, 0, len(edits))}
+
+	for _, e := range edits {
+		switch e.op {
+		case INSERT:
+			hunk.Lines = append(hunk.Lines, "+"+dst[e.dstIndex])
+			hunk.DstLines++
+		case MOVE:
+			hunk.Lines = append(hunk.Lines, " "+src[e.srcIndex])
+			hunk.SrcLines++
+			hunk.DstLines++
+		case DELETE:
+			hunk.Lines = append(hunk.Lines, "-"+src[e.srcIndex])
+			hunk.SrcLines++
+		}
+	}
+
+	hunk.SrcStart = edits[0].srcIndex
+	if hunk.SrcLines > 0 {
+		hunk.SrcStart++
+	}
+	hunk.DstStart = edits[0].dstIndex
+	if hunk.DstLines > 0 {
+		hunk.DstStart++
+	}
+
+	return hunk
+}
+
+func hunkRange(start, lines int) string {
+	if lines == 1 {
+		return strconv.Itoa(start)
+	}
+	return strconv.Itoa(start) + "," + strconv.Itoa(lines)
+}
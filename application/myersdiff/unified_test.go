@@ -0,0 +1,111 @@
+package myersdiff
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func numberedLines(n int) []string {
+	lines := make([]string, 0, n)
+	for i := 1; i <= n; i++ {
+		lines = append(lines, strconv.Itoa(i))
+	}
+
+	return lines
+}
+
+func replaceLine(lines []string, index int, line string) []string {
+	replaced := slices.Clone(lines)
+	replaced[index] = line
+
+	return replaced
+}
+
+func TestGenerateHunks(t *testing.T) {
+	ten := numberedLines(10)
+
+	tests := []struct {
+		name        string
+		src         []string
+		dst         []string
+		context     int
+		wantHeaders []string
+		wantLines   [][]string
+	}{
+		{
+			name:        "identical files",
+			src:         ten,
+			dst:         ten,
+			context:     3,
+			wantHeaders: []string{},
+			wantLines:   [][]string{},
+		},
+		{
+			name:        "change with default context",
+			src:         ten,
+			dst:         replaceLine(ten, 4, "five"),
+			context:     3,
+			wantHeaders: []string{"@@ -2,7 +2,7 @@"},
+			wantLines:   [][]string{{" 2", " 3", " 4", "-5", "+five", " 6", " 7", " 8"}},
+		},
+		{
+			name:        "change without context",
+			src:         ten,
+			dst:         replaceLine(ten, 4, "five"),
+			context:     0,
+			wantHeaders: []string{"@@ -5 +5 @@"},
+			wantLines:   [][]string{{"-5", "+five"}},
+		},
+		{
+			name:        "negative context is no context",
+			src:         ten,
+			dst:         replaceLine(ten, 4, "five"),
+			context:     -1,
+			wantHeaders: []string{"@@ -5 +5 @@"},
+			wantLines:   [][]string{{"-5", "+five"}},
+		},
+		{
+			name:        "distant changes make separate hunks",
+			src:         ten,
+			dst:         replaceLine(replaceLine(ten, 0, "one"), 9, "ten"),
+			context:     1,
+			wantHeaders: []string{"@@ -1,2 +1,2 @@", "@@ -9,2 +9,2 @@"},
+			wantLines:   [][]string{{"-1", "+one", " 2"}, {" 9", "-10", "+ten"}},
+		},
+		{
+			name:        "close changes share a hunk",
+			src:         ten,
+			dst:         replaceLine(replaceLine(ten, 0, "one"), 9, "ten"),
+			context:     4,
+			wantHeaders: []string{"@@ -1,10 +1,10 @@"},
+			wantLines:   [][]string{{"-1", "+one", " 2", " 3", " 4", " 5", " 6", " 7", " 8", " 9", "-10", "+ten"}},
+		},
+		{
+			name:        "new file",
+			src:         nil,
+			dst:         []string{"a"},
+			context:     3,
+			wantHeaders: []string{"@@ -0,0 +1 @@"},
+			wantLines:   [][]string{{"+a"}},
+		},
+	}
+
+	myers := NewMyersDiffCalculator()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hunks := myers.GenerateHunks(tt.src, tt.dst, tt.context)
+			if len(hunks) != len(tt.wantHeaders) {
+				t.Fatalf("GenerateHunks() returned %d hunks, want %d", len(hunks), len(tt.wantHeaders))
+			}
+			for i, hunk := range hunks {
+				if hunk.Header() != tt.wantHeaders[i] {
+					t.Errorf("hunk %d header = %q, want %q", i, hunk.Header(), tt.wantHeaders[i])
+				}
+				if !slices.Equal(hunk.Lines, tt.wantLines[i]) {
+					t.Errorf("hunk %d lines = %q, want %q", i, hunk.Lines, tt.wantLines[i])
+				}
+			}
+		})
+	}
+}
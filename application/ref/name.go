@@ -0,0 +1,32 @@
+package ref
+
+import (
+	"git-light/util"
+	"strings"
+)
+
+// IsValidName reports whether given branch or tag name can be stored under refs and told
+// apart from revision expressions. like git, names can't hold a component starting with a
+// dot, "..", "@{", whitespace or characters used by revisions and patterns, and can't be
+// HEAD or start with a dash.
+func IsValidName(name string) bool {
+	if name == "" || name == util.Head || name == "@" || strings.HasPrefix(name, "-") || strings.HasSuffix(name, ".lock") {
+		return false
+	}
+	if strings.Contains(name, "..") || strings.Contains(name, "@{") || strings.ContainsAny(name, " ~^:?*[\\") {
+		return false
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f {
+			return false
+		}
+	}
+
+	for _, component := range strings.Split(name, "/") {
+		if component == "" || strings.HasPrefix(component, ".") {
+			return false
+		}
+	}
+
+	return true
+}
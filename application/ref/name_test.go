@@ -0,0 +1,42 @@
+package ref
+
+import "testing"
+
+func TestIsValidName(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{name: "main", want: true},
+		{name: "feature/login", want: true},
+		{name: "v1.0", want: true},
+		{name: "fix-1", want: true},
+		{name: "", want: false},
+		{name: "HEAD", want: false},
+		{name: "@", want: false},
+		{name: "-b", want: false},
+		{name: "../x", want: false},
+		{name: "a..b", want: false},
+		{name: "HEAD~1", want: false},
+		{name: "main^2", want: false},
+		{name: "x@{1}", want: false},
+		{name: "a:b", want: false},
+		{name: "with space", want: false},
+		{name: "star*", want: false},
+		{name: "/leading", want: false},
+		{name: "trailing/", want: false},
+		{name: "double//slash", want: false},
+		{name: ".hidden", want: false},
+		{name: "feature/.hidden", want: false},
+		{name: "main.lock", want: false},
+		{name: "tab\tname", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsValidName(tt.name); got != tt.want {
+				t.Errorf("IsValidName(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
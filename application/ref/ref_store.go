@@ -0,0 +1,266 @@
+package ref
+
+import (
+	"errors"
+	"git-light/application/repository"
+	"git-light/util"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// SymbolicRefPrefix starts HEAD when it points to a branch, otherwise HEAD holds the
+// hash of the checked out commit directly.
+const SymbolicRefPrefix = "ref: "
+
+// Head is the resolved state of HEAD. Branch is empty when HEAD is detached and Hash is
+// "nil" when current branch doesn't have any commits yet.
+type Head struct {
+	Branch string
+	Hash   string
+}
+
+func (h Head) IsDetached() bool {
+	return h.Branch == ""
+}
+
+// RefStore reads and moves HEAD, branches, tags and stash. every movement to a commit is appended to
+// the reflog of the moved refs together with identity of the actor and given reason.
+type RefStore interface {
+	ReadHead() (Head, error)
+	AttachHead(branchName string, reason string) error
+	DetachHead(commitHash string, reason string) error
+	UpdateHead(commitHash string, reason string) error
+	ReadBranch(branchName string) (string, error)
+	WriteBranch(branchName string, commitHash string, reason string) error
+	DeleteBranch(branchName string) error
+	BranchExists(branchName string) bool
+	ReadTag(tagName string) (string, error)
+	WriteTag(tagName string, objectHash string) error
+	DeleteTag(tagName string) error
+	ListTags() ([]string, error)
+	ReadReflog(refName string) ([]ReflogEntry, error)
+	ReadStash() (string, error)
+	PushStash(commitHash string, reason string) error
+	DropStash(n int) error
+	WithIdentity(identity string) RefStore
+}
+
+type refStore struct {
+	repo     repository.Repository
+	identity string
+}
+
+func NewRefStore(repo repository.Repository) RefStore {
+	identity := "unknown"
+	if currentUser, err := user.Current(); err == nil {
+		identity = currentUser.Username
+	}
+
+	return refStore{repo: repo, identity: identity}
+}
+
+// WithIdentity returns a store which records given identity in reflog entries, like the
+// committer of a commit.
+func (rs refStore) WithIdentity(identity string) RefStore {
+	rs.identity = identity
+	return rs
+}
+
+// ReadHead resolves HEAD. older versions wrote a bare branch name or commit hash into HEAD,
+// a bare name is treated as a branch if such a branch exists.
+func (rs refStore) ReadHead() (Head, error) {
+	lines, err := rs.repo.GetFileLines(filepath.Join(util.BaseFilePath, util.Head))
+	if err != nil {
+		return Head{}, repositoryError(err)
+	}
+	if len(lines) == 0 {
+		return Head{}, errors.New("HEAD is empty")
+	}
+
+	branchName, symbolic := strings.CutPrefix(lines[0], SymbolicRefPrefix+util.BranchFolder+"/")
+	if !symbolic && !rs.BranchExists(lines[0]) {
+		return Head{Hash: lines[0]}, nil
+	}
+	if !symbolic {
+		branchName = lines[0]
+	}
+
+	commitHash, err := rs.ReadBranch(branchName)
+	if err != nil {
+		commitHash = "nil"
+	}
+
+	return Head{Branch: branchName, Hash: commitHash}, nil
+}
+
+func (rs refStore) AttachHead(branchName string, reason string) error {
+	newHash, err := rs.ReadBranch(branchName)
+	if err != nil {
+		return util.BranchNotFoundError{Name: branchName}
+	}
+	oldHash := rs.headHash()
+
+	err = rs.repo.WriteToFile(filepath.Join(util.BaseFilePath, util.Head), []string{SymbolicRefPrefix + util.BranchFolder + "/" + branchName})
+	if err != nil {
+		return err
+	}
+
+	return rs.appendReflog(util.Head, oldHash, newHash, reason)
+}
+
+func (rs refStore) DetachHead(commitHash string, reason string) error {
+	oldHash := rs.headHash()
+
+	err := rs.repo.WriteToFile(filepath.Join(util.BaseFilePath, util.Head), []string{commitHash})
+	if err != nil {
+		return err
+	}
+
+	return rs.appendReflog(util.Head, oldHash, commitHash, reason)
+}
+
+// UpdateHead moves current branch to given commit, or HEAD itself when it is detached.
+func (rs refStore) UpdateHead(commitHash string, reason string) error {
+	head, err := rs.ReadHead()
+	if err != nil {
+		return err
+	}
+	if head.IsDetached() {
+		return rs.DetachHead(commitHash, reason)
+	}
+
+	err = rs.WriteBranch(head.Branch, commitHash, reason)
+	if err != nil {
+		return err
+	}
+
+	return rs.appendReflog(util.Head, head.Hash, commitHash, reason)
+}
+
+func (rs refStore) ReadBranch(branchName string) (string, error) {
+	lines, err := rs.repo.GetFileLines(filepath.Join(util.BaseFilePath, util.BranchFolder, branchName))
+	if os.IsNotExist(err) {
+		return "", util.BranchNotFoundError{Name: branchName}
+	}
+	if err != nil {
+		return "", err
+	}
+	if len(lines) == 0 {
+		return "", errors.New("branch file is empty: " + branchName)
+	}
+
+	return lines[0], nil
+}
+
+func (rs refStore) WriteBranch(branchName string, commitHash string, reason string) error {
+	oldHash, err := rs.ReadBranch(branchName)
+	if err != nil {
+		oldHash = "nil"
+	}
+
+	err = rs.repo.WriteToFile(filepath.Join(util.BaseFilePath, util.BranchFolder, branchName), []string{commitHash})
+	if err != nil {
+		return err
+	}
+
+	return rs.appendReflog(branchName, oldHash, commitHash, reason)
+}
+
+// DeleteBranch removes given branch together with its reflog.
+func (rs refStore) DeleteBranch(branchName string) error {
+	err := rs.repo.DeleteFiles(filepath.Join(util.BaseFilePath, util.BranchFolder, branchName))
+	if err != nil {
+		return err
+	}
+
+	err = rs.repo.DeleteFiles(reflogPath(branchName))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (rs refStore) BranchExists(branchName string) bool {
+	_, err := rs.ReadBranch(branchName)
+	return err == nil
+}
+
+// ReadTag returns hash of the object given tag points to, which is a commit for lightweight
+// tags and a tag object for annotated ones.
+func (rs refStore) ReadTag(tagName string) (string, error) {
+	lines, err := rs.repo.GetFileLines(filepath.Join(util.BaseFilePath, util.TagFolder, tagName))
+	if err != nil {
+		return "", err
+	}
+	if len(lines) == 0 {
+		return "", errors.New("tag file is empty: " + tagName)
+	}
+
+	return lines[0], nil
+}
+
+func (rs refStore) WriteTag(tagName string, objectHash string) error {
+	return rs.repo.WriteToFile(filepath.Join(util.BaseFilePath, util.TagFolder, tagName), []string{objectHash})
+}
+
+func (rs refStore) DeleteTag(tagName string) error {
+	tagPath := filepath.Join(util.BaseFilePath, util.TagFolder, tagName)
+	err := rs.repo.DeleteFiles(tagPath)
+	if err != nil {
+		return err
+	}
+
+	return rs.repo.PruneEmptyDirs(filepath.Dir(tagPath))
+}
+
+// ListTags returns names of all tags sorted, tags created before tags folder existed
+// don't exist so a missing folder is an empty list.
+func (rs refStore) ListTags() ([]string, error) {
+	tagsDir := filepath.Join(util.BaseFilePath, util.TagFolder)
+	if _, err := os.Stat(tagsDir); os.IsNotExist(err) {
+		if _, err := os.Stat(util.BaseFilePath); os.IsNotExist(err) {
+			return nil, util.ErrNotARepository
+		}
+		return []string{}, nil
+	}
+
+	tagFiles, err := rs.repo.ListAllFiles(tagsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	tagNames := make([]string, 0, len(tagFiles))
+	for _, tagFile := range tagFiles {
+		tagName, err := filepath.Rel(tagsDir, tagFile)
+		if err != nil {
+			return nil, err
+		}
+		tagNames = append(tagNames, filepath.ToSlash(tagName))
+	}
+	slices.Sort(tagNames)
+
+	return tagNames, nil
+}
+
+func (rs refStore) headHash() string {
+	head, err := rs.ReadHead()
+	if err != nil {
+		return "nil"
+	}
+
+	return head.Hash
+}
+
+// repositoryError replaces given error with util.ErrNotARepository when it was caused by
+// running outside of a repository.
+func repositoryError(err error) error {
+	if _, statErr := os.Stat(util.BaseFilePath); os.IsNotExist(statErr) {
+		return util.ErrNotARepository
+	}
+
+	return err
+}
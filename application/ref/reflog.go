@@ -0,0 +1,81 @@
+package ref
+
+import (
+	"errors"
+	"fmt"
+	"git-light/util"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ReflogEntry is one movement of a ref. each entry is kept on its own line of the ref's
+// log file as "<old hash> <new hash> <unix time> <identity>\t<reason>".
+type ReflogEntry struct {
+	OldHash  string
+	NewHash  string
+	Date     time.Time
+	Identity string
+	Reason   string
+}
+
+// ReadReflog returns entries of given ref, oldest first. ref is HEAD, stash or a branch name.
+func (rs refStore) ReadReflog(refName string) ([]ReflogEntry, error) {
+	lines, err := rs.repo.GetFileLines(reflogPath(refName))
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]ReflogEntry, 0, len(lines))
+	for _, line := range lines {
+		entry, err := parseReflogEntry(line)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// appendReflog records a movement of given ref. movements to no commit, like creating
+// the initial branch, aren't recorded.
+func (rs refStore) appendReflog(refName string, oldHash string, newHash string, reason string) error {
+	if newHash == "nil" {
+		return nil
+	}
+
+	entry := ReflogEntry{OldHash: oldHash, NewHash: newHash, Date: time.Now(), Identity: rs.identity, Reason: reason}
+	return rs.repo.AppendLine(reflogPath(refName), formatReflogEntry(entry))
+}
+
+// reflogPath returns log file of given ref, logs of HEAD and stash are kept next to the
+// folder of branch logs.
+func reflogPath(refName string) string {
+	if refName == util.Head || refName == util.Stash {
+		return filepath.Join(util.BaseFilePath, util.LogFolder, refName)
+	}
+
+	return filepath.Join(util.BaseFilePath, util.LogFolder, util.BranchFolder, refName)
+}
+
+func parseReflogEntry(line string) (ReflogEntry, error) {
+	header, reason, _ := strings.Cut(line, "\t")
+	fields := strings.SplitN(header, " ", 4)
+	if len(fields) != 4 {
+		return ReflogEntry{}, errors.New("malformed reflog entry: " + line)
+	}
+
+	seconds, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return ReflogEntry{}, errors.New("malformed reflog entry: " + line)
+	}
+
+	return ReflogEntry{OldHash: fields[0], NewHash: fields[1], Date: time.Unix(seconds, 0), Identity: fields[3], Reason: reason}, nil
+}
+
+func formatReflogEntry(entry ReflogEntry) string {
+	reason := strings.ReplaceAll(entry.Reason, "\n", " ")
+	return fmt.Sprintf("%s %s %d %s\t%s", entry.OldHash, entry.NewHash, entry.Date.Unix(), entry.Identity, reason)
+}
@@ -0,0 +1,72 @@
+package ref
+
+import (
+	"errors"
+	"fmt"
+	"git-light/util"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ReadStash returns the newest stash entry. older entries are kept in the reflog of stash,
+// stash@{n} is the n-th newest one.
+func (rs refStore) ReadStash() (string, error) {
+	lines, err := rs.repo.GetFileLines(filepath.Join(util.BaseFilePath, util.Stash))
+	if err != nil {
+		return "", err
+	}
+	if len(lines) == 0 {
+		return "", errors.New("stash file is empty")
+	}
+
+	return lines[0], nil
+}
+
+// PushStash makes given commit the newest stash entry, reason is the entry's message.
+func (rs refStore) PushStash(commitHash string, reason string) error {
+	oldHash, err := rs.ReadStash()
+	if err != nil {
+		oldHash = "nil"
+	}
+
+	err = rs.repo.WriteToFile(filepath.Join(util.BaseFilePath, util.Stash), []string{commitHash})
+	if err != nil {
+		return err
+	}
+
+	return rs.appendReflog(util.Stash, oldHash, commitHash, reason)
+}
+
+// DropStash removes stash@{n}. stash points to the newest remaining entry afterwards and
+// is removed together with its reflog when no entries are left.
+func (rs refStore) DropStash(n int) error {
+	entries, err := rs.ReadReflog(util.Stash)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if n < 0 || n >= len(entries) {
+		return fmt.Errorf("stash@{%d} does not exist", n)
+	}
+	entries = slices.Delete(entries, len(entries)-1-n, len(entries)-n)
+
+	stashPath := filepath.Join(util.BaseFilePath, util.Stash)
+	if len(entries) == 0 {
+		err = rs.repo.DeleteFiles(stashPath)
+		if err != nil {
+			return err
+		}
+		return rs.repo.DeleteFiles(reflogPath(util.Stash))
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		lines = append(lines, formatReflogEntry(entry))
+	}
+	err = rs.repo.WriteToFile(reflogPath(util.Stash), lines)
+	if err != nil {
+		return err
+	}
+
+	return rs.repo.WriteToFile(stashPath, []string{entries[len(entries)-1].NewHash})
+}
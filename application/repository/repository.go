@@ -14,12 +14,18 @@ import (
 
 type Repository interface {
 	GetFileLines(p string) ([]string, error)
+	ReadFile(p string) ([]byte, error)
 	WriteToFile(p string, content []string) error
+	WriteFile(p string, content []byte) error
+	AppendLine(p string, line string) error
 	CompressAndSaveToFile(data interface{}, filename string) error
 	DecompressFromFileAndConvert(filename string, data interface{}) error
 	ListAllFiles(root string) ([]string, error)
+	ListFiles(root string, skip func(path string, isDir bool) bool) ([]string, error)
 	MoveFiles(sourceDir, destinationDir string) error
+	Rename(oldPath, newPath string) error
 	DeleteFiles(path string) error
+	PruneEmptyDirs(dir string) error
 }
 
 type repository struct {
@@ -30,31 +36,32 @@ func NewRepository() Repository {
 }
 
 func (r repository) GetFileLines(p string) ([]string, error) {
-	f, err := os.Open(p)
-
+	content, err := os.ReadFile(p)
 	if err != nil {
 		return nil, err
 	}
 
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-
-	var lines []string
-
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+	lines := strings.Split(string(content), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
 	}
-	scanner.Scan()
-
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	for i, line := range lines {
+		lines[i] = strings.TrimSuffix(line, "\r")
 	}
 
 	return lines, nil
 }
 
+func (r repository) ReadFile(p string) ([]byte, error) {
+	return os.ReadFile(p)
+}
+
 func (r repository) WriteToFile(p string, content []string) error {
+	err := os.MkdirAll(filepath.Dir(p), 0755)
+	if err != nil {
+		return err
+	}
+
 	file, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
 	if err != nil {
 		return err
@@ -74,6 +81,33 @@ func (r repository) WriteToFile(p string, content []string) error {
 	return nil
 }
 
+func (r repository) WriteFile(p string, content []byte) error {
+	err := os.MkdirAll(filepath.Dir(p), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(p, content, 0644)
+}
+
+// AppendLine adds given line to the end of a file, file and its directories are created
+// if they don't exist.
+func (r repository) AppendLine(p string, line string) error {
+	err := os.MkdirAll(filepath.Dir(p), 0755)
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(line + "\n")
+	return err
+}
+
 func (r repository) CompressAndSaveToFile(data interface{}, filename string) error {
 	var buf bytes.Buffer
 	encoder := gob.NewEncoder(&buf)
@@ -120,6 +154,14 @@ func (r repository) DecompressFromFileAndConvert(filename string, data interface
 }
 
 func (r repository) ListAllFiles(root string) ([]string, error) {
+	return r.ListFiles(root, func(path string, isDir bool) bool {
+		return false
+	})
+}
+
+// ListFiles walks given root and returns files for which skip returns false, directories
+// for which skip returns true are not walked at all.
+func (r repository) ListFiles(root string, skip func(path string, isDir bool) bool) ([]string, error) {
 	var files []string
 
 	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
@@ -128,11 +170,10 @@ func (r repository) ListAllFiles(root string) ([]string, error) {
 		}
 
 		if info.IsDir() {
-			if strings.HasPrefix(path, filepath.Join(root, ".git")) ||
-				strings.HasPrefix(path, filepath.Join(root, ".idea")) {
+			if path != root && skip(path, true) {
 				return filepath.SkipDir
 			}
-		} else {
+		} else if !skip(path, false) {
 			files = append(files, path)
 		}
 		return nil
@@ -140,6 +181,8 @@ func (r repository) ListAllFiles(root string) ([]string, error) {
 	return files, err
 }
 
+// MoveFiles moves everything under sourceDir into destinationDir, nested directories are
+// created in destination and removed from source after their content is moved.
 func (r repository) MoveFiles(sourceDir, destinationDir string) error {
 	source, err := os.Open(sourceDir)
 	if err != nil {
@@ -161,24 +204,72 @@ func (r repository) MoveFiles(sourceDir, destinationDir string) error {
 		sourcePath := filepath.Join(sourceDir, fileInfo.Name())
 		destinationPath := filepath.Join(destinationDir, fileInfo.Name())
 
-		sourceFile, err := os.Open(sourcePath)
-		if err != nil {
-			return err
+		if fileInfo.IsDir() {
+			err = os.MkdirAll(destinationPath, 0755)
+			if err != nil {
+				return err
+			}
+			err = r.MoveFiles(sourcePath, destinationPath)
+			if err != nil {
+				return err
+			}
+			err = os.Remove(sourcePath)
+		} else {
+			err = r.moveFile(sourcePath, destinationPath)
 		}
-		defer sourceFile.Close()
-
-		destinationFile, err := os.Create(destinationPath)
 		if err != nil {
 			return err
 		}
-		defer destinationFile.Close()
+	}
 
-		_, err = io.Copy(destinationFile, sourceFile)
-		if err != nil {
+	return nil
+}
+
+func (r repository) moveFile(sourcePath, destinationPath string) error {
+	sourceFile, err := os.Open(sourcePath)
+	if err != nil {
+		return err
+	}
+	defer sourceFile.Close()
+
+	destinationFile, err := os.Create(destinationPath)
+	if err != nil {
+		return err
+	}
+	defer destinationFile.Close()
+
+	_, err = io.Copy(destinationFile, sourceFile)
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(sourcePath)
+}
+
+// Rename moves a file or directory to given path, missing parent directories of the
+// new path are created.
+func (r repository) Rename(oldPath, newPath string) error {
+	err := os.MkdirAll(filepath.Dir(newPath), 0755)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(oldPath, newPath)
+}
+
+func (r repository) DeleteFiles(path string) error {
+	return os.Remove(path)
+}
+
+// PruneEmptyDirs removes given directory and its parents as long as they are empty,
+// current directory is never removed.
+func (r repository) PruneEmptyDirs(dir string) error {
+	for dir = filepath.Clean(dir); dir != "." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		entries, err := os.ReadDir(dir)
+		if err != nil || len(entries) > 0 {
 			return err
 		}
-
-		err = os.Remove(sourcePath)
+		err = os.Remove(dir)
 		if err != nil {
 			return err
 		}
@@ -186,7 +277,3 @@ func (r repository) MoveFiles(sourceDir, destinationDir string) error {
 
 	return nil
 }
-
-func (r repository) DeleteFiles(path string) error {
-	return os.Remove(path)
-}
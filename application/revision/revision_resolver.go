@@ -0,0 +1,292 @@
+package revision
+
+import (
+	"errors"
+	"fmt"
+	"git-light/application/ref"
+	"git-light/application/repository"
+	"git-light/util"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// MinAbbreviatedHashLength is the shortest hash prefix accepted as a revision.
+const MinAbbreviatedHashLength = 4
+
+var (
+	reflogSuffix = regexp.MustCompile(`^(.*)@\{(\d+)\}$`)
+	hexPattern   = regexp.MustCompile(`^[0-9a-f]+$`)
+)
+
+// RevisionResolver turns revision expressions into commit hashes. supported expressions:
+//
+//	HEAD, @             current commit
+//	<branch>            commit a branch points to
+//	<tag>               commit a tag points to, annotated tags are peeled
+//	<hash>              full or unique abbreviated commit hash
+//	<ref>@{n}, @{n}     n-th previous value of a ref from its reflog
+//	stash, stash@{n}    newest or n-th newest stash entry
+//	<rev>~n             n-th first parent of a revision
+//	<rev>^n             n-th parent of a revision, ^0 is the revision itself
+//
+// suffixes may be chained like HEAD~2^2.
+type RevisionResolver interface {
+	Resolve(revision string) (string, error)
+	ResolveRange(expression string) (string, string, error)
+	IsRange(expression string) bool
+}
+
+type revisionResolver struct {
+	repo repository.Repository
+	refs ref.RefStore
+}
+
+func NewRevisionResolver(repo repository.Repository, refs ref.RefStore) RevisionResolver {
+	return revisionResolver{repo: repo, refs: refs}
+}
+
+// commitParents is the part of a commit object revisions walk over, gob fills only the
+// fields it has and fails for objects which aren't commits.
+type commitParents struct {
+	PreviousCommit string
+	Parents        []string
+}
+
+// tagTarget is the part of an annotated tag object needed to peel it.
+type tagTarget struct {
+	Object string
+}
+
+func (rr revisionResolver) Resolve(revision string) (string, error) {
+	if revision == "" {
+		return "", errors.New("empty revision")
+	}
+
+	end := strings.IndexAny(revision, "~^")
+	if end == -1 {
+		end = len(revision)
+	}
+
+	commitHash, err := rr.resolveBase(revision[:end])
+	if err != nil {
+		return "", err
+	}
+
+	suffixes := revision[end:]
+	for len(suffixes) > 0 {
+		operator := suffixes[0]
+		suffixes = suffixes[1:]
+
+		digits := len(suffixes) - len(strings.TrimLeft(suffixes, "0123456789"))
+		n := 1
+		if digits > 0 {
+			n, err = strconv.Atoi(suffixes[:digits])
+			if err != nil {
+				return "", errors.New("invalid revision: " + revision)
+			}
+		}
+		suffixes = suffixes[digits:]
+
+		if operator == '~' {
+			commitHash, err = rr.nthAncestor(commitHash, n, revision)
+		} else {
+			commitHash, err = rr.nthParent(commitHash, n, revision)
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return commitHash, nil
+}
+
+// ResolveRange resolves A..B into the commit to exclude and the commit to include. a side
+// left empty stands for HEAD.
+func (rr revisionResolver) ResolveRange(expression string) (string, string, error) {
+	from, to, found := strings.Cut(expression, "..")
+	if !found {
+		return "", "", errors.New("not a revision range: " + expression)
+	}
+	if from == "" {
+		from = util.Head
+	}
+	if to == "" {
+		to = util.Head
+	}
+
+	fromHash, err := rr.Resolve(from)
+	if err != nil {
+		return "", "", err
+	}
+	toHash, err := rr.Resolve(to)
+	if err != nil {
+		return "", "", err
+	}
+
+	return fromHash, toHash, nil
+}
+
+func (rr revisionResolver) IsRange(expression string) bool {
+	return strings.Contains(expression, "..")
+}
+
+func (rr revisionResolver) resolveBase(base string) (string, error) {
+	if matches := reflogSuffix.FindStringSubmatch(base); matches != nil {
+		n, err := strconv.Atoi(matches[2])
+		if err != nil {
+			return "", errors.New("invalid reflog index: " + base)
+		}
+		return rr.resolveReflog(matches[1], n)
+	}
+
+	if base == util.Head || base == "@" {
+		head, err := rr.refs.ReadHead()
+		if err != nil {
+			return "", err
+		}
+		if head.Hash == "nil" {
+			return "", errors.New("HEAD does not point to a commit yet")
+		}
+		return head.Hash, nil
+	}
+
+	if base == util.Stash {
+		commitHash, err := rr.refs.ReadStash()
+		if err != nil {
+			return "", errors.New("no stash entries found")
+		}
+		return commitHash, nil
+	}
+
+	if commitHash, err := rr.refs.ReadBranch(base); err == nil {
+		if commitHash == "nil" {
+			return "", errors.New("branch " + base + " does not have any commits yet")
+		}
+		return commitHash, nil
+	}
+
+	if objectHash, err := rr.refs.ReadTag(base); err == nil {
+		return rr.peel(objectHash)
+	}
+
+	return rr.resolveHash(base)
+}
+
+// peel follows annotated tag objects until a commit is reached.
+func (rr revisionResolver) peel(objectHash string) (string, error) {
+	if _, err := rr.readCommit(objectHash); err == nil {
+		return objectHash, nil
+	}
+
+	var tag tagTarget
+	err := rr.repo.DecompressFromFileAndConvert(filepath.Join(util.BaseFilePath, util.ObjectFolder, objectHash), &tag)
+	if err != nil {
+		return "", errors.New("object " + objectHash + " is not a commit")
+	}
+
+	return rr.peel(tag.Object)
+}
+
+// resolveReflog returns value of given ref n movements ago. without a ref name current
+// branch is used, or HEAD when it is detached.
+func (rr revisionResolver) resolveReflog(refName string, n int) (string, error) {
+	if refName == "" {
+		head, err := rr.refs.ReadHead()
+		if err != nil {
+			return "", err
+		}
+		refName = head.Branch
+		if head.IsDetached() {
+			refName = util.Head
+		}
+	} else if refName == "@" {
+		refName = util.Head
+	}
+
+	entries, err := rr.refs.ReadReflog(refName)
+	if err != nil || n >= len(entries) {
+		return "", fmt.Errorf("log for %s only has %d entries", refName, len(entries))
+	}
+
+	commitHash := entries[len(entries)-1-n].NewHash
+	if commitHash == "nil" {
+		return "", fmt.Errorf("%s@{%d} does not point to a commit", refName, n)
+	}
+
+	return commitHash, nil
+}
+
+// resolveHash finds the commit or tag object whose hash starts with given prefix, prefixes
+// matching more than one of them are rejected.
+func (rr revisionResolver) resolveHash(prefix string) (string, error) {
+	if len(prefix) < MinAbbreviatedHashLength || !hexPattern.MatchString(prefix) {
+		return "", util.UnknownRevisionError{Revision: prefix}
+	}
+
+	entries, err := os.ReadDir(filepath.Join(util.BaseFilePath, util.ObjectFolder))
+	if err != nil {
+		return "", err
+	}
+
+	candidates := make([]string, 0)
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), prefix) {
+			continue
+		}
+		if _, err := rr.peel(entry.Name()); err == nil {
+			candidates = append(candidates, entry.Name())
+		}
+	}
+
+	switch len(candidates) {
+	case 0:
+		return "", util.UnknownRevisionError{Revision: prefix}
+	case 1:
+		return rr.peel(candidates[0])
+	default:
+		return "", fmt.Errorf("short hash %s is ambiguous, candidates are: %s", prefix, strings.Join(candidates, ", "))
+	}
+}
+
+func (rr revisionResolver) nthAncestor(commitHash string, n int, revision string) (string, error) {
+	for i := 0; i < n; i++ {
+		parent, err := rr.nthParent(commitHash, 1, revision)
+		if err != nil {
+			return "", err
+		}
+		commitHash = parent
+	}
+
+	return commitHash, nil
+}
+
+func (rr revisionResolver) nthParent(commitHash string, n int, revision string) (string, error) {
+	if n == 0 {
+		return commitHash, nil
+	}
+
+	commit, err := rr.readCommit(commitHash)
+	if err != nil {
+		return "", errors.New("failed to read commit " + commitHash + " while resolving " + revision)
+	}
+
+	parents := commit.Parents
+	if len(parents) == 0 && commit.PreviousCommit != "" && commit.PreviousCommit != "nil" {
+		parents = []string{commit.PreviousCommit}
+	}
+	if n > len(parents) {
+		return "", errors.New("revision " + revision + " goes beyond history of " + commitHash)
+	}
+
+	return parents[n-1], nil
+}
+
+func (rr revisionResolver) readCommit(commitHash string) (commitParents, error) {
+	var commit commitParents
+	err := rr.repo.DecompressFromFileAndConvert(filepath.Join(util.BaseFilePath, util.ObjectFolder, commitHash), &commit)
+
+	return commit, err
+}
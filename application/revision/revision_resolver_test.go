@@ -0,0 +1,172 @@
+package revision
+
+import (
+	"errors"
+	"git-light/application/ref"
+	"git-light/application/repository"
+	"git-light/util"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// history of the test repository, main is a merge of c2 and c3 which both branch off c1:
+//
+//	c1 <- c2 <- m
+//	 ^          |
+//	 +--- c3 <--+
+var (
+	c1 = strings.Repeat("1", 40)
+	c2 = "abcd1" + strings.Repeat("2", 35)
+	c3 = "abcd2" + strings.Repeat("3", 35)
+	m  = "9f" + strings.Repeat("4", 38)
+)
+
+// setupRepository creates the test repository in a temporary directory and makes it the
+// working directory until the test ends.
+func setupRepository(t *testing.T) RevisionResolver {
+	t.Helper()
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = os.Chdir(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
+
+	err = os.MkdirAll(filepath.Join(util.BaseFilePath, util.ObjectFolder), 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := repository.NewRepository()
+	commits := map[string]commitParents{
+		c1: {PreviousCommit: "nil"},
+		c2: {PreviousCommit: c1, Parents: []string{c1}},
+		c3: {PreviousCommit: c1, Parents: []string{c1}},
+		m:  {PreviousCommit: c2, Parents: []string{c2, c3}},
+	}
+	for hash, commit := range commits {
+		err = repo.CompressAndSaveToFile(commit, filepath.Join(util.BaseFilePath, util.ObjectFolder, hash))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	refs := ref.NewRefStore(repo)
+	for _, hash := range []string{c1, c2, m} {
+		err = refs.WriteBranch(util.DefaultBranchName, hash, "commit")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = refs.WriteBranch("side", c3, "branch")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = refs.AttachHead(util.DefaultBranchName, "checkout")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewRevisionResolver(repo, refs)
+}
+
+func TestResolve(t *testing.T) {
+	rr := setupRepository(t)
+
+	tests := []struct {
+		revision     string
+		want         string
+		wantErr      bool
+		wantNotFound bool
+	}{
+		{revision: "HEAD", want: m},
+		{revision: "@", want: m},
+		{revision: "main", want: m},
+		{revision: "side", want: c3},
+		{revision: "HEAD~", want: c2},
+		{revision: "HEAD~1", want: c2},
+		{revision: "HEAD~2", want: c1},
+		{revision: "HEAD^", want: c2},
+		{revision: "HEAD^2", want: c3},
+		{revision: "HEAD^0", want: m},
+		{revision: "HEAD^2~1", want: c1},
+		{revision: "main@{0}", want: m},
+		{revision: "main@{1}", want: c2},
+		{revision: "main@{2}", want: c1},
+		{revision: "@{1}", want: c2},
+		{revision: "1111", want: c1},
+		{revision: "abcd1", want: c2},
+		{revision: c3, want: c3},
+		{revision: "abcd", wantErr: true},
+		{revision: "111", wantErr: true, wantNotFound: true},
+		{revision: "HEAD~3", wantErr: true},
+		{revision: "HEAD^3", wantErr: true},
+		{revision: "main@{3}", wantErr: true},
+		{revision: "missing", wantErr: true, wantNotFound: true},
+		{revision: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.revision, func(t *testing.T) {
+			got, err := rr.Resolve(tt.revision)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Resolve(%q) = %q, want an error", tt.revision, got)
+				} else if errors.Is(err, util.ErrBranchNotFound) != tt.wantNotFound {
+					t.Errorf("Resolve(%q) returned error %v, matching ErrBranchNotFound should be %v", tt.revision, err, tt.wantNotFound)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Resolve(%q) returned error: %v", tt.revision, err)
+			}
+			if got != tt.want {
+				t.Errorf("Resolve(%q) = %q, want %q", tt.revision, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveRange(t *testing.T) {
+	rr := setupRepository(t)
+
+	tests := []struct {
+		expression string
+		wantFrom   string
+		wantTo     string
+		wantErr    bool
+	}{
+		{expression: "side..main", wantFrom: c3, wantTo: m},
+		{expression: "HEAD~2..HEAD^2", wantFrom: c1, wantTo: c3},
+		{expression: "..side", wantFrom: m, wantTo: c3},
+		{expression: "side..", wantFrom: c3, wantTo: m},
+		{expression: "main", wantErr: true},
+		{expression: "missing..main", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			from, to, err := rr.ResolveRange(tt.expression)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("ResolveRange(%q) = %q, %q, want an error", tt.expression, from, to)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ResolveRange(%q) returned error: %v", tt.expression, err)
+			}
+			if from != tt.wantFrom || to != tt.wantTo {
+				t.Errorf("ResolveRange(%q) = %q, %q, want %q, %q", tt.expression, from, to, tt.wantFrom, tt.wantTo)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package tag
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"strconv"
+	"time"
+)
+
+// Tag is an annotated tag object. lightweight tags have no object, their ref points to
+// the commit directly.
+type Tag struct {
+	Object  string
+	Name    string
+	Tagger  string
+	Date    time.Time
+	Message string
+}
+
+func (t Tag) CalculateHashForTag() string {
+	serialized := t.Serialize()
+	hasher := sha1.New()
+	hasher.Write([]byte("tag " + strconv.Itoa(len(serialized)) + "\x00"))
+	hasher.Write(serialized)
+
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// Serialize returns canonical form of the tag which is used for hashing, free form fields
+// are length prefixed like they are for commits.
+func (t Tag) Serialize() []byte {
+	var buf bytes.Buffer
+	buf.WriteString("object " + t.Object + "\n")
+	buf.WriteString("tag " + strconv.Itoa(len(t.Name)) + " " + t.Name + "\n")
+	buf.WriteString("tagger " + strconv.Itoa(len(t.Tagger)) + " " + t.Tagger + "\n")
+	buf.WriteString("date " + strconv.FormatInt(t.Date.UnixNano(), 10) + "\n")
+	buf.WriteString("message " + strconv.Itoa(len(t.Message)) + "\n" + t.Message)
+
+	return buf.Bytes()
+}
@@ -0,0 +1,111 @@
+package tag
+
+import (
+	"errors"
+	"fmt"
+	"git-light/application/ref"
+	"git-light/application/repository"
+	"git-light/application/revision"
+	"git-light/util"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+type TagService interface {
+	CreateTag(tagName string, target string, message string, tagger string, force bool) error
+	DeleteTag(tagName string) error
+	ListTags(pattern string) ([]string, error)
+}
+
+type tagService struct {
+	repo      repository.Repository
+	refs      ref.RefStore
+	revisions revision.RevisionResolver
+}
+
+func NewTagService(repo repository.Repository, refs ref.RefStore, revisions revision.RevisionResolver) TagService {
+	return tagService{
+		repo:      repo,
+		refs:      refs,
+		revisions: revisions,
+	}
+}
+
+// CreateTag points given tag to target revision, HEAD is used when target is empty. a tag
+// object holding tagger, date and message is created when message is given, otherwise
+// the tag is lightweight and points to the commit directly.
+func (ts tagService) CreateTag(tagName string, target string, message string, tagger string, force bool) error {
+	if !ref.IsValidName(tagName) {
+		return errors.New("invalid tag name: " + tagName)
+	}
+	if _, err := ts.refs.ReadTag(tagName); err == nil && !force {
+		return errors.New("tag " + tagName + " already exists, use --force to replace it")
+	}
+
+	if target == "" {
+		target = util.Head
+	}
+	commitHash, err := ts.revisions.Resolve(target)
+	if err != nil {
+		return err
+	}
+
+	objectHash := commitHash
+	if message != "" {
+		tag := Tag{Object: commitHash, Name: tagName, Tagger: tagger, Date: time.Now(), Message: message}
+		objectHash = tag.CalculateHashForTag()
+		err = ts.repo.CompressAndSaveToFile(tag, filepath.Join(util.BaseFilePath, util.ObjectFolder, objectHash))
+		if err != nil {
+			return fmt.Errorf("failed to save tag object: %w", err)
+		}
+	}
+
+	err = ts.refs.WriteTag(tagName, objectHash)
+	if err != nil {
+		return fmt.Errorf("couldn't create tag: %w", err)
+	}
+
+	return nil
+}
+
+func (ts tagService) DeleteTag(tagName string) error {
+	objectHash, err := ts.refs.ReadTag(tagName)
+	if err != nil {
+		return errors.New("no such tag: " + tagName)
+	}
+
+	err = ts.refs.DeleteTag(tagName)
+	if err != nil {
+		return fmt.Errorf("couldn't delete tag: %w", err)
+	}
+	fmt.Printf("Deleted tag %s (was %s)\n", tagName, objectHash)
+
+	return nil
+}
+
+// ListTags prints names of tags matching given glob pattern, every tag is printed when
+// pattern is empty.
+func (ts tagService) ListTags(pattern string) ([]string, error) {
+	tagNames, err := ts.refs.ListTags()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get list of tags: %w", err)
+	}
+
+	matched := make([]string, 0, len(tagNames))
+	for _, tagName := range tagNames {
+		if pattern != "" {
+			ok, err := path.Match(pattern, tagName)
+			if err != nil {
+				return nil, errors.New("invalid pattern: " + pattern)
+			}
+			if !ok {
+				continue
+			}
+		}
+		fmt.Println(tagName)
+		matched = append(matched, tagName)
+	}
+
+	return matched, nil
+}
@@ -1,10 +1,6 @@
 package cmd
 
 import (
-	"git-light/application/checkout"
-	"git-light/application/myersdiff"
-	"git-light/application/repository"
-
 	"github.com/spf13/cobra"
 )
 
@@ -12,11 +8,9 @@ var addCmd = &cobra.Command{
 	Use:   "add",
 	Short: "adds given file to stage",
 	Long:  `this command calculates diffs according to given files and saves them into staging area if any difference exist between working directory and previous commit.`,
-	Run: func(cmd *cobra.Command, args []string) {
-		repo := repository.NewRepository()
-		myersDiff := myersdiff.NewMyersDiffCalculator()
-		commitService := checkout.NewCommitService(repo, myersDiff)
-		commitService.AddToStage(args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commitService := newCommitService()
+		return commitService.AddToStage(args)
 	},
 }
 
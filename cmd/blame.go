@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var blameLineRange string
+
+var blameCmd = &cobra.Command{
+	Use:   "blame <path> [revision]",
+	Short: "shows which commit last changed each line of a file",
+	Long:  `this command prints every line of given file in given revision or HEAD, together with short hash, committer and date of the commit which introduced the line and its line number.`,
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commitService := newCommitService()
+		var rev string
+		if len(args) > 1 {
+			rev = args[1]
+		}
+		return commitService.Blame(args[0], rev, blameLineRange)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(blameCmd)
+
+	blameCmd.Flags().StringVarP(&blameLineRange, "lines", "L", "", "Limit output to lines start,end or start,+count")
+}
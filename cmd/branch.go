@@ -1,8 +1,6 @@
 package cmd
 
 import (
-	"git-light/application/branch"
-	"git-light/application/repository"
 	"github.com/spf13/cobra"
 )
 
@@ -14,26 +12,27 @@ var (
 var branchCmd = &cobra.Command{
 	Use:   "branch",
 	Short: "Manage branches",
-	Long:  `The branch command allows you to create, delete, and list branches.`,
-	Run: func(cmd *cobra.Command, args []string) {
+	Long:  `The branch command allows you to create, delete, and list branches. new branches start from current commit unless a revision is given as start point.`,
+	Args:  cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
 		if listAllBranches {
-			repo := repository.NewRepository()
-			branchService := branch.NewBranchService(repo)
-			_ = branchService.ListAllBranches()
-			return
+			branchService := newBranchService()
+			_, err := branchService.ListAllBranches()
+			return err
 		}
 		if deleteBranch != "" {
-			repo := repository.NewRepository()
-			branchService := branch.NewBranchService(repo)
-			branchService.DeleteBranch(deleteBranch)
-			return
+			branchService := newBranchService()
+			return branchService.DeleteBranch(deleteBranch)
 		}
 		if len(args) > 0 {
-			repo := repository.NewRepository()
-			branchService := branch.NewBranchService(repo)
-			branchService.CreateBranch(args[0])
+			branchService := newBranchService()
+			var startPoint string
+			if len(args) > 1 {
+				startPoint = args[1]
+			}
+			return branchService.CreateBranch(args[0], startPoint)
 		} else {
-			_ = cmd.Help()
+			return cmd.Help()
 		}
 	},
 }
@@ -1,26 +1,24 @@
 package cmd
 
 import (
-	"git-light/application/checkout"
-	"git-light/application/myersdiff"
-	"git-light/application/repository"
-
 	"github.com/spf13/cobra"
 )
 
+var checkoutForce bool
+
 var checkoutCmd = &cobra.Command{
 	Use:   "checkout",
 	Short: "checkouts for given commit hash or branch name",
-	Long:  `this command first looks for branches and then checks for commits to retrieve files from object store.`,
+	Long:  `this command first looks for branches and then checks for commits to retrieve files from object store. files which differ between current and given commit are updated, files missing in given commit are deleted and untracked files are left alone. checkout is refused if local changes would be overwritten, unless --force is given.`,
 	Args:  cobra.ExactArgs(1),
-	Run: func(cmd *cobra.Command, args []string) {
-		repo := repository.NewRepository()
-		myersDiff := myersdiff.NewMyersDiffCalculator()
-		commitService := checkout.NewCommitService(repo, myersDiff)
-		commitService.Checkout(args[0])
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commitService := newCommitService()
+		return commitService.Checkout(args[0], checkoutForce)
 	},
 }
 
 func init() {
 	RootCmd.AddCommand(checkoutCmd)
+
+	checkoutCmd.Flags().BoolVarP(&checkoutForce, "force", "f", false, "Discard local changes which would be overwritten")
 }
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	continueCherryPick bool
+	abortCherryPick    bool
+)
+
+var cherryPickCmd = &cobra.Command{
+	Use:   "cherry-pick <revision | A..B>... | cherry-pick --continue | cherry-pick --abort",
+	Short: "applies changes of given commits on top of current branch",
+	Long:  `this command replays changes each given commit made to its parent on top of current branch and commits them with the original message and committer. on conflicts it stops, resolved files should be added and cherry-pick continued with --continue, --abort moves current branch back to where it was.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commitService := newCommitService()
+		switch {
+		case continueCherryPick:
+			return commitService.ContinueCherryPick()
+		case abortCherryPick:
+			return commitService.AbortCherryPick()
+		case len(args) == 0:
+			return cmd.Help()
+		default:
+			return commitService.CherryPick(args)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(cherryPickCmd)
+
+	cherryPickCmd.Flags().BoolVar(&continueCherryPick, "continue", false, "Commit resolved cherry-pick and pick remaining commits")
+	cherryPickCmd.Flags().BoolVar(&abortCherryPick, "abort", false, "Abort cherry-pick and restore previous state")
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	diffStaged       bool
+	diffContextLines int
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [revision] [revision] | diff A..B",
+	Short: "shows changes between working tree, stage and commits",
+	Long:  `this command prints unified diffs. without arguments it compares working directory with staging area, with --staged it compares staging area with last commit and given two revisions or a range A..B it compares them.`,
+	Args:  cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if diffContextLines < 0 {
+			return usageError(cmd, "-U requires a non-negative number of context lines")
+		}
+		commitService := newCommitService()
+		return commitService.Diff(args, diffStaged, diffContextLines)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().BoolVar(&diffStaged, "staged", false, "Compare staging area with last commit")
+	diffCmd.Flags().IntVarP(&diffContextLines, "unified", "U", 3, "Number of context lines")
+}
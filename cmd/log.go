@@ -2,25 +2,39 @@ package cmd
 
 import (
 	"git-light/application/checkout"
-	"git-light/application/myersdiff"
-	"git-light/application/repository"
 
 	"github.com/spf13/cobra"
 )
 
+var logOptions checkout.LogOptions
+
 var logCmd = &cobra.Command{
-	Use:   "log",
+	Use:   "log [revision | A..B]... [-- path...]",
 	Short: "prints commit history",
-	Long:  `this command prints log history in descending order`,
-	Args:  cobra.ExactArgs(0),
-	Run: func(cmd *cobra.Command, args []string) {
-		repo := repository.NewRepository()
-		myersDiff := myersdiff.NewMyersDiffCalculator()
-		commitService := checkout.NewCommitService(repo, myersDiff)
-		commitService.Log()
+	Long:  `this command prints log history in descending order starting from given revisions or HEAD. for a range A..B, commits reachable from B but not from A are printed. paths after -- limit history to commits changing them.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commitService := newCommitService()
+		options := logOptions
+		options.Revisions = args
+		if dash := cmd.ArgsLenAtDash(); dash != -1 {
+			options.Revisions = args[:dash]
+			options.Paths = args[dash:]
+		}
+		return commitService.Log(options)
 	},
 }
 
 func init() {
 	RootCmd.AddCommand(logCmd)
+
+	logCmd.Flags().BoolVar(&logOptions.NameStatus, "name-status", false, "Show added, modified and deleted files of each commit")
+	logCmd.Flags().BoolVar(&logOptions.Oneline, "oneline", false, "Print each commit on a single line")
+	logCmd.Flags().StringVar(&logOptions.Format, "format", "", "Print commits using given template, like %h %s")
+	logCmd.Flags().IntVarP(&logOptions.MaxCount, "max-count", "n", -1, "Limit number of printed commits")
+	logCmd.Flags().StringVar(&logOptions.Author, "author", "", "Print only commits whose author matches given pattern")
+	logCmd.Flags().StringVar(&logOptions.Grep, "grep", "", "Print only commits whose message matches given pattern")
+	logCmd.Flags().StringVar(&logOptions.Since, "since", "", "Print only commits newer than given date")
+	logCmd.Flags().StringVar(&logOptions.Until, "until", "", "Print only commits older than given date")
+	logCmd.Flags().BoolVar(&logOptions.All, "all", false, "Start from all branches, tags and HEAD")
+	logCmd.Flags().BoolVar(&logOptions.Graph, "graph", false, "Draw history as a graph next to commits")
 }
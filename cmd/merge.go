@@ -0,0 +1,34 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	abortMerge     bool
+	mergeCommitter string
+)
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge",
+	Short: "merges given branch into current branch",
+	Long:  `this command finds merge base of current branch and given branch, merges each file with three-way merge and records the result as a commit with two parents. conflicting hunks are written with conflict markers and should be committed after resolving them.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commitService := newCommitService()
+		if abortMerge {
+			return commitService.AbortMerge()
+		}
+		if len(args) == 0 {
+			return cmd.Help()
+		}
+		return commitService.Merge(args[0], mergeCommitter)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(mergeCmd)
+
+	mergeCmd.Flags().BoolVar(&abortMerge, "abort", false, "Abort current merge and restore previous state")
+	mergeCmd.Flags().StringVarP(&mergeCommitter, "committer", "c", "default committer", "Committer's email")
+}
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "rewrites commits with current commit hashing scheme",
+	Long:  `this command rewrites commits created by older versions of git-light so that their hashes cover tree, parents, committer, date and message. branches are updated to point to rewritten commits.`,
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commitService := newCommitService()
+		return commitService.Migrate()
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(migrateCmd)
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var mvForce bool
+
+var mvCmd = &cobra.Command{
+	Use:   "mv <source> <destination>",
+	Short: "moves or renames a tracked file or directory",
+	Long:  `this command moves given tracked file or directory in working directory and stages the rename. if destination is an existing directory, source is moved into it.`,
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commitService := newCommitService()
+		return commitService.Move(args[0], args[1], mvForce)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(mvCmd)
+
+	mvCmd.Flags().BoolVarP(&mvForce, "force", "f", false, "Overwrite destination if it exists")
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	continueRebase bool
+	skipRebase     bool
+	abortRebase    bool
+	rebaseTodoFile string
+)
+
+var rebaseCmd = &cobra.Command{
+	Use:   "rebase <upstream> [--todo file] | rebase --continue | rebase --skip | rebase --abort",
+	Short: "replays commits of current branch on top of another commit",
+	Long:  `this command replays commits of current branch which are not reachable from upstream on top of upstream one by one and moves current branch to the result, merge commits are left out. with --todo, steps are read from given file, each line is "pick <rev>", "squash <rev>", "drop <rev>" or "reword <rev> <message>". on conflicts it stops, resolved files should be added and rebase continued with --continue, --skip drops the stopped commit and --abort restores the branch.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commitService := newCommitService()
+		switch {
+		case continueRebase:
+			return commitService.ContinueRebase()
+		case skipRebase:
+			return commitService.SkipRebase()
+		case abortRebase:
+			return commitService.AbortRebase()
+		case len(args) == 0:
+			return cmd.Help()
+		default:
+			return commitService.Rebase(args[0], rebaseTodoFile)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(rebaseCmd)
+
+	rebaseCmd.Flags().BoolVar(&continueRebase, "continue", false, "Commit resolved step and run remaining steps")
+	rebaseCmd.Flags().BoolVar(&skipRebase, "skip", false, "Drop the stopped commit and run remaining steps")
+	rebaseCmd.Flags().BoolVar(&abortRebase, "abort", false, "Abort rebase and restore previous state")
+	rebaseCmd.Flags().StringVar(&rebaseTodoFile, "todo", "", "Read rebase steps from given file")
+}
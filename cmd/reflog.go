@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var reflogCmd = &cobra.Command{
+	Use:   "reflog [ref]",
+	Short: "shows movements of HEAD or a branch",
+	Long:  `this command prints every commit given ref pointed to, newest first, together with the reason it moved. entries can be used as revisions with <ref>@{n}. HEAD is shown when no ref is given.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commitService := newCommitService()
+		var refName string
+		if len(args) > 0 {
+			refName = args[0]
+		}
+		return commitService.Reflog(refName)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(reflogCmd)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"errors"
+	"git-light/application/checkout"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	resetSoft  bool
+	resetMixed bool
+	resetHard  bool
+)
+
+var resetCmd = &cobra.Command{
+	Use:   "reset [--soft | --mixed | --hard] [revision] | reset [revision] [--] path...",
+	Short: "moves current branch or unstages files",
+	Long:  `this command moves current branch to given revision or HEAD. --soft keeps staging area and working directory, --mixed which is the default also resets staging area and --hard resets working directory too. given paths, their staged changes are reset to given revision or HEAD instead.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commitService := newCommitService()
+		revisionResolver := newRevisionResolver()
+
+		var rev string
+		var paths []string
+		if dash := cmd.ArgsLenAtDash(); dash != -1 {
+			if dash > 1 {
+				return errors.New("only one revision can be given before --")
+			}
+			if dash == 1 {
+				rev = args[0]
+			}
+			paths = args[dash:]
+		} else if len(args) > 0 {
+			if _, err := revisionResolver.Resolve(args[0]); err == nil {
+				rev = args[0]
+				paths = args[1:]
+			} else {
+				paths = args
+			}
+		}
+
+		mode := checkout.ResetMixed
+		modeCount := 0
+		for flag, flagMode := range map[*bool]checkout.ResetMode{&resetSoft: checkout.ResetSoft, &resetMixed: checkout.ResetMixed, &resetHard: checkout.ResetHard} {
+			if *flag {
+				mode = flagMode
+				modeCount++
+			}
+		}
+		if modeCount > 1 {
+			return errors.New("--soft, --mixed and --hard can not be used together")
+		}
+
+		if len(paths) > 0 {
+			if resetSoft || resetHard {
+				return errors.New("can not do a " + string(mode) + " reset with paths")
+			}
+			return commitService.ResetPaths(rev, paths)
+		}
+		return commitService.Reset(rev, mode)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(resetCmd)
+
+	resetCmd.Flags().BoolVar(&resetSoft, "soft", false, "Move only current branch")
+	resetCmd.Flags().BoolVar(&resetMixed, "mixed", false, "Move current branch and reset staging area")
+	resetCmd.Flags().BoolVar(&resetHard, "hard", false, "Move current branch, reset staging area and working directory")
+}
@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	continueRevert  bool
+	abortRevert     bool
+	revertCommitter string
+)
+
+var revertCmd = &cobra.Command{
+	Use:   "revert <revision> | revert --continue | revert --abort",
+	Short: "creates a commit undoing changes of given commit",
+	Long:  `this command applies inverse of changes given commit made to its parent on top of current branch with three-way merge and commits the result. on conflicts it stops, resolved files should be added and the revert finished with --continue or dropped with --abort.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commitService := newCommitService()
+		switch {
+		case continueRevert:
+			return commitService.ContinueRevert(revertCommitter)
+		case abortRevert:
+			return commitService.AbortRevert()
+		case len(args) == 0:
+			return cmd.Help()
+		default:
+			return commitService.Revert(args[0], revertCommitter)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(revertCmd)
+
+	revertCmd.Flags().BoolVar(&continueRevert, "continue", false, "Commit resolved revert")
+	revertCmd.Flags().BoolVar(&abortRevert, "abort", false, "Abort current revert and restore previous state")
+	revertCmd.Flags().StringVarP(&revertCommitter, "committer", "c", "default committer", "Committer's email")
+}
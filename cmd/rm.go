@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	rmCached bool
+	rmForce  bool
+)
+
+var rmCmd = &cobra.Command{
+	Use:   "rm <path>...",
+	Short: "removes given files from working directory and stage",
+	Long:  `this command stages deletion of given tracked files or directories and removes them from working directory. with --cached files are only removed from stage and kept in working directory. files having changes which are not committed are kept unless --force is given.`,
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commitService := newCommitService()
+		return commitService.Remove(args, rmCached, rmForce)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(rmCmd)
+
+	rmCmd.Flags().BoolVar(&rmCached, "cached", false, "Only remove from stage, keep files in working directory")
+	rmCmd.Flags().BoolVarP(&rmForce, "force", "f", false, "Remove files even if they have changes which are not committed")
+}
@@ -1,22 +1,106 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"git-light/application/attributes"
+	"git-light/application/branch"
+	"git-light/application/checkout"
+	"git-light/application/hook"
+	"git-light/application/ignore"
+	"git-light/application/myersdiff"
+	"git-light/application/ref"
+	"git-light/application/repository"
+	"git-light/application/revision"
+	"git-light/application/tag"
+	"git-light/util"
 	"os"
 
 	"github.com/spf13/cobra"
 )
 
+// exit codes of failed commands, scripts can tell conflicts and other expected failures
+// apart from unexpected ones.
+const (
+	exitFailure        = 1
+	exitConflict       = 2
+	exitNothingStaged  = 3
+	exitBranchNotFound = 4
+	exitNotARepository = 128
+)
+
 var RootCmd = &cobra.Command{
 	Use:     "git-light",
 	Short:   "git-light is a basic version control system.",
 	Long:    `this application is a light weight version of git. It basically uses myers-diff algorithm to calculate differences between text files and saves them as deltas to a git like object store.`,
 	Version: "0.1",
+	// usage is printed for invalid flags and arguments only, not for failures of the
+	// command itself.
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		cmd.SilenceUsage = true
+	},
+	SilenceErrors: true,
 }
 
 func Execute() {
 	if err := RootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, "error: "+err.Error())
+		os.Exit(exitCode(err))
+	}
+}
+
+// newCommitService wires a commit service working on the repository in current directory.
+func newCommitService() checkout.CommitService {
+	repo := repository.NewRepository()
+	myersDiff := myersdiff.NewMyersDiffCalculator()
+	hookRunner := hook.NewHookRunner()
+	attributeResolver := attributes.NewAttributeResolver(repo)
+	ignoreMatcher := ignore.NewIgnoreMatcher(repo)
+	refStore := ref.NewRefStore(repo)
+	revisionResolver := revision.NewRevisionResolver(repo, refStore)
+	return checkout.NewCommitService(repo, myersDiff, hookRunner, attributeResolver, ignoreMatcher, refStore, revisionResolver)
+}
+
+// newBranchService wires a branch service working on the repository in current directory.
+func newBranchService() branch.BranchService {
+	repo := repository.NewRepository()
+	refStore := ref.NewRefStore(repo)
+	revisionResolver := revision.NewRevisionResolver(repo, refStore)
+	return branch.NewBranchService(repo, refStore, revisionResolver)
+}
+
+// newTagService wires a tag service working on the repository in current directory.
+func newTagService() tag.TagService {
+	repo := repository.NewRepository()
+	refStore := ref.NewRefStore(repo)
+	revisionResolver := revision.NewRevisionResolver(repo, refStore)
+	return tag.NewTagService(repo, refStore, revisionResolver)
+}
+
+// newRevisionResolver wires a revision resolver working on the repository in current directory.
+func newRevisionResolver() revision.RevisionResolver {
+	repo := repository.NewRepository()
+	return revision.NewRevisionResolver(repo, ref.NewRefStore(repo))
+}
+
+// usageError returns an error for invalid arguments, usage of cmd is printed with it like
+// it is for invalid flags.
+func usageError(cmd *cobra.Command, message string) error {
+	cmd.SilenceUsage = false
+	return errors.New(message)
+}
+
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, util.ErrConflict):
+		return exitConflict
+	case errors.Is(err, util.ErrNothingStaged):
+		return exitNothingStaged
+	case errors.Is(err, util.ErrBranchNotFound):
+		return exitBranchNotFound
+	case errors.Is(err, util.ErrNotARepository):
+		return exitNotARepository
 	}
+
+	return exitFailure
 }
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"git-light/util"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "generic failure", err: errors.New("boom"), want: exitFailure},
+		{name: "conflict", err: util.ConflictError{Message: "merge failed", Paths: []string{"a.txt"}}, want: exitConflict},
+		{name: "wrapped conflict", err: fmt.Errorf("rebase: %w", util.ConflictError{Message: "stopped"}), want: exitConflict},
+		{name: "nothing staged", err: fmt.Errorf("%w, you should first add your changes", util.ErrNothingStaged), want: exitNothingStaged},
+		{name: "branch not found", err: util.BranchNotFoundError{Name: "feature"}, want: exitBranchNotFound},
+		{name: "wrapped branch not found", err: fmt.Errorf("checkout: %w", util.BranchNotFoundError{Name: "feature"}), want: exitBranchNotFound},
+		{name: "unknown revision", err: util.UnknownRevisionError{Revision: "nosuch"}, want: exitBranchNotFound},
+		{name: "not a repository", err: fmt.Errorf("failed to read HEAD: %w", util.ErrNotARepository), want: exitNotARepository},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.want {
+				t.Errorf("exitCode(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var showContextLines int
+
+var showCmd = &cobra.Command{
+	Use:   "show [revision | revision:path]",
+	Short: "shows a commit or a file in a commit",
+	Long:  `this command prints metadata of given commit together with its diff against first parent, HEAD is shown when no revision is given. for revision:path exact content of the file in that commit is printed.`,
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if showContextLines < 0 {
+			return usageError(cmd, "-U requires a non-negative number of context lines")
+		}
+		commitService := newCommitService()
+		object := "HEAD"
+		if len(args) > 0 {
+			object = args[0]
+		}
+		return commitService.Show(object, showContextLines)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(showCmd)
+
+	showCmd.Flags().IntVarP(&showContextLines, "unified", "U", 3, "Number of context lines")
+}
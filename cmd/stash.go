@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	stashMessage   string
+	stashCommitter string
+)
+
+var stashCmd = &cobra.Command{
+	Use:   "stash [push | list | apply | pop | drop] [stash@{n}]",
+	Short: "shelves staged and working directory changes",
+	Long:  `this command saves staged and working directory changes of tracked files as a stash entry and resets them to HEAD. entries are listed newest first, apply merges an entry back into working directory, pop also drops it when it applies without conflicts and drop removes it. the newest entry is used when none is given.`,
+	Args:  cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commitService := newCommitService()
+
+		subcommand := "push"
+		if len(args) > 0 {
+			subcommand = args[0]
+		}
+		var entry string
+		if len(args) > 1 {
+			entry = args[1]
+		}
+
+		switch subcommand {
+		case "push":
+			return commitService.StashPush(stashMessage, stashCommitter)
+		case "list":
+			return commitService.StashList()
+		case "apply":
+			return commitService.StashApply(entry)
+		case "pop":
+			return commitService.StashPop(entry)
+		case "drop":
+			return commitService.StashDrop(entry)
+		default:
+			return usageError(cmd, "unknown stash subcommand: "+subcommand)
+		}
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(stashCmd)
+
+	stashCmd.Flags().StringVarP(&stashMessage, "message", "m", "", "Stash entry message")
+	stashCmd.Flags().StringVarP(&stashCommitter, "committer", "c", "default committer", "Committer's email")
+}
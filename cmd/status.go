@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "shows the working tree status",
+	Long:  `this command compares working directory, staging area and last commit on current branch and lists untracked, modified, deleted and staged files.`,
+	Args:  cobra.ExactArgs(0),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		commitService := newCommitService()
+		_, err := commitService.Status()
+		return err
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(statusCmd)
+}
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	tagAnnotate bool
+	tagMessage  string
+	tagTagger   string
+	deleteTag   string
+	listTags    bool
+	forceTag    bool
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag [name] [revision]",
+	Short: "Manage tags",
+	Long:  `The tag command allows you to create, delete, and list tags. tags point to current commit unless a revision is given. with a message an annotated tag object holding tagger, date and message is created, otherwise the tag is lightweight. listing accepts a glob pattern like v1.*`,
+	Args:  cobra.MaximumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tagService := newTagService()
+
+		if deleteTag != "" {
+			return tagService.DeleteTag(deleteTag)
+		}
+		if listTags || len(args) == 0 {
+			var pattern string
+			if len(args) > 0 {
+				pattern = args[0]
+			}
+			_, err := tagService.ListTags(pattern)
+			return err
+		}
+		if tagAnnotate && tagMessage == "" {
+			return usageError(cmd, "annotated tag requires -m")
+		}
+
+		var target string
+		if len(args) > 1 {
+			target = args[1]
+		}
+		return tagService.CreateTag(args[0], target, tagMessage, tagTagger, forceTag)
+	},
+}
+
+func init() {
+	RootCmd.AddCommand(tagCmd)
+
+	tagCmd.Flags().BoolVarP(&tagAnnotate, "annotate", "a", false, "Create an annotated tag, requires a message")
+	tagCmd.Flags().StringVarP(&tagMessage, "message", "m", "", "Tag message, creates an annotated tag")
+	tagCmd.Flags().StringVarP(&tagTagger, "tagger", "c", "default committer", "Tagger's email")
+	tagCmd.Flags().StringVarP(&deleteTag, "delete", "d", "", "Delete a tag")
+	tagCmd.Flags().BoolVarP(&listTags, "list", "l", false, "List tags matching given pattern")
+	tagCmd.Flags().BoolVarP(&forceTag, "force", "f", false, "Replace an existing tag")
+}
@@ -3,9 +3,24 @@ package util
 const (
 	BaseFilePath      = ".git-light"
 	BranchFolder      = "branches"
+	TagFolder         = "tags"
 	ObjectFolder      = "objects"
 	StageFolder       = "stage"
 	TempFolder        = "temp"
+	HookFolder        = "hooks"
+	LogFolder         = "logs"
+	SequencerFolder   = "sequencer"
+	RebaseFolder      = "rebase"
 	DefaultBranchName = "main"
 	Head              = "HEAD"
+	Stash             = "stash"
+	MergeHead         = "MERGE_HEAD"
+	RevertHead        = "REVERT_HEAD"
+	CherryPickHead    = "CHERRY_PICK_HEAD"
+	MergeMessage      = "MERGE_MSG"
+	UnmergedPaths     = "UNMERGED"
+	CommitMessageFile = "COMMIT_EDITMSG"
+	AttributesFile    = ".gitlightattributes"
+	IgnoreFile        = ".gitlightignore"
+	ExcludesFileEnv   = "GIT_LIGHT_EXCLUDES_FILE"
 )
@@ -0,0 +1,63 @@
+package util
+
+import (
+	"errors"
+	"strings"
+)
+
+// errors returned by services which callers may need to tell apart, they are wrapped
+// with more context so errors.Is should be used to check them.
+var (
+	ErrNotARepository = errors.New("not a git-light repository")
+	ErrNothingStaged  = errors.New("nothing staged")
+	ErrBranchNotFound = errors.New("no such branch")
+	ErrConflict       = errors.New("conflict")
+)
+
+// BranchNotFoundError is returned when a branch given by name doesn't exist.
+type BranchNotFoundError struct {
+	Name string
+}
+
+func (e BranchNotFoundError) Error() string {
+	return ErrBranchNotFound.Error() + ": " + e.Name
+}
+
+func (e BranchNotFoundError) Is(target error) bool {
+	return target == ErrBranchNotFound
+}
+
+// UnknownRevisionError is returned when a revision names no branch, tag or commit. a bare
+// name most likely stands for a branch, so it matches ErrBranchNotFound.
+type UnknownRevisionError struct {
+	Revision string
+}
+
+func (e UnknownRevisionError) Error() string {
+	return "unknown revision: " + e.Revision
+}
+
+func (e UnknownRevisionError) Is(target error) bool {
+	return target == ErrBranchNotFound
+}
+
+// ConflictError is returned when an operation stopped because of conflicting changes.
+// Message tells what stopped and how to go on, Paths are the conflicting files.
+type ConflictError struct {
+	Message string
+	Paths   []string
+}
+
+func (e ConflictError) Error() string {
+	var builder strings.Builder
+	builder.WriteString(e.Message)
+	for _, path := range e.Paths {
+		builder.WriteString("\n\t" + path)
+	}
+
+	return builder.String()
+}
+
+func (e ConflictError) Is(target error) bool {
+	return target == ErrConflict
+}
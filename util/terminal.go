@@ -0,0 +1,14 @@
+package util
+
+import "os"
+
+// IsTerminal reports whether given file is a terminal, output written to pipes and files
+// should not contain colour codes.
+func IsTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}